@@ -3,12 +3,16 @@ module github.com/meateam/search-service
 go 1.13
 
 require (
+	github.com/blevesearch/bleve v1.0.14
 	github.com/golang/protobuf v1.4.2
 	github.com/grpc-ecosystem/go-grpc-middleware v1.1.0
+	github.com/mattn/go-sqlite3 v1.14.6
 	github.com/meateam/elasticsearch-logger v1.1.3-0.20190901111807-4e8b84fb9fda
 	github.com/olivere/elastic/v7 v7.0.0
 	github.com/sirupsen/logrus v1.4.2
 	github.com/spf13/viper v1.5.0
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013
 	google.golang.org/grpc v1.27.0
 	google.golang.org/protobuf v1.25.0 // indirect
+	gopkg.in/yaml.v2 v2.2.4
 )
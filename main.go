@@ -1,9 +1,13 @@
 package main
 
 import (
+	"os"
+
 	"github.com/meateam/search-service/server"
 )
 
 func main() {
-	server.NewServer(nil).Serve(nil)
+	if err := server.NewServer(nil).Serve(nil); err != nil {
+		os.Exit(1)
+	}
 }
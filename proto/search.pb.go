@@ -24,6 +24,488 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+// Refresh is when the changes made by a write request become visible to search.
+type Refresh int32
+
+const (
+	// REFRESH_DEFAULT uses the server's configured refresh policy.
+	Refresh_REFRESH_DEFAULT Refresh = 0
+	// REFRESH_NONE makes the changes visible on the next periodic refresh.
+	Refresh_REFRESH_NONE Refresh = 1
+	// REFRESH_WAIT_FOR responds once the changes are made visible by a refresh.
+	Refresh_REFRESH_WAIT_FOR Refresh = 2
+	// REFRESH_IMMEDIATE refreshes immediately, making the changes visible before responding.
+	Refresh_REFRESH_IMMEDIATE Refresh = 3
+)
+
+var Refresh_name = map[int32]string{
+	0: "REFRESH_DEFAULT",
+	1: "REFRESH_NONE",
+	2: "REFRESH_WAIT_FOR",
+	3: "REFRESH_IMMEDIATE",
+}
+
+var Refresh_value = map[string]int32{
+	"REFRESH_DEFAULT":   0,
+	"REFRESH_NONE":      1,
+	"REFRESH_WAIT_FOR":  2,
+	"REFRESH_IMMEDIATE": 3,
+}
+
+func (x Refresh) String() string {
+	return proto.EnumName(Refresh_name, int32(x))
+}
+
+func (Refresh) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{0}
+}
+
+type ReindexRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReindexRequest) Reset()         { *m = ReindexRequest{} }
+func (m *ReindexRequest) String() string { return proto.CompactTextString(m) }
+func (*ReindexRequest) ProtoMessage()    {}
+func (*ReindexRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{0}
+}
+
+func (m *ReindexRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReindexRequest.Unmarshal(m, b)
+}
+func (m *ReindexRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReindexRequest.Marshal(b, m, deterministic)
+}
+func (m *ReindexRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReindexRequest.Merge(m, src)
+}
+func (m *ReindexRequest) XXX_Size() int {
+	return xxx_messageInfo_ReindexRequest.Size(m)
+}
+func (m *ReindexRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReindexRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReindexRequest proto.InternalMessageInfo
+
+type ReindexResponse struct {
+	Source               string   `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Destination          string   `protobuf:"bytes,2,opt,name=destination,proto3" json:"destination,omitempty"`
+	Total                int64    `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReindexResponse) Reset()         { *m = ReindexResponse{} }
+func (m *ReindexResponse) String() string { return proto.CompactTextString(m) }
+func (*ReindexResponse) ProtoMessage()    {}
+func (*ReindexResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{1}
+}
+
+func (m *ReindexResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReindexResponse.Unmarshal(m, b)
+}
+func (m *ReindexResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReindexResponse.Marshal(b, m, deterministic)
+}
+func (m *ReindexResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReindexResponse.Merge(m, src)
+}
+func (m *ReindexResponse) XXX_Size() int {
+	return xxx_messageInfo_ReindexResponse.Size(m)
+}
+func (m *ReindexResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReindexResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReindexResponse proto.InternalMessageInfo
+
+func (m *ReindexResponse) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *ReindexResponse) GetDestination() string {
+	if m != nil {
+		return m.Destination
+	}
+	return ""
+}
+
+func (m *ReindexResponse) GetTotal() int64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+type MigrateRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MigrateRequest) Reset()         { *m = MigrateRequest{} }
+func (m *MigrateRequest) String() string { return proto.CompactTextString(m) }
+func (*MigrateRequest) ProtoMessage()    {}
+func (*MigrateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{2}
+}
+
+func (m *MigrateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MigrateRequest.Unmarshal(m, b)
+}
+func (m *MigrateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MigrateRequest.Marshal(b, m, deterministic)
+}
+func (m *MigrateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MigrateRequest.Merge(m, src)
+}
+func (m *MigrateRequest) XXX_Size() int {
+	return xxx_messageInfo_MigrateRequest.Size(m)
+}
+func (m *MigrateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MigrateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MigrateRequest proto.InternalMessageInfo
+
+type MigrateResponse struct {
+	FromVersion          int64    `protobuf:"varint,1,opt,name=fromVersion,proto3" json:"fromVersion,omitempty"`
+	ToVersion            int64    `protobuf:"varint,2,opt,name=toVersion,proto3" json:"toVersion,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MigrateResponse) Reset()         { *m = MigrateResponse{} }
+func (m *MigrateResponse) String() string { return proto.CompactTextString(m) }
+func (*MigrateResponse) ProtoMessage()    {}
+func (*MigrateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{3}
+}
+
+func (m *MigrateResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MigrateResponse.Unmarshal(m, b)
+}
+func (m *MigrateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MigrateResponse.Marshal(b, m, deterministic)
+}
+func (m *MigrateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MigrateResponse.Merge(m, src)
+}
+func (m *MigrateResponse) XXX_Size() int {
+	return xxx_messageInfo_MigrateResponse.Size(m)
+}
+func (m *MigrateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_MigrateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MigrateResponse proto.InternalMessageInfo
+
+func (m *MigrateResponse) GetFromVersion() int64 {
+	if m != nil {
+		return m.FromVersion
+	}
+	return 0
+}
+
+func (m *MigrateResponse) GetToVersion() int64 {
+	if m != nil {
+		return m.ToVersion
+	}
+	return 0
+}
+
+type CreateSnapshotRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateSnapshotRequest) Reset()         { *m = CreateSnapshotRequest{} }
+func (m *CreateSnapshotRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateSnapshotRequest) ProtoMessage()    {}
+func (*CreateSnapshotRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{4}
+}
+
+func (m *CreateSnapshotRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateSnapshotRequest.Unmarshal(m, b)
+}
+func (m *CreateSnapshotRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateSnapshotRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateSnapshotRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateSnapshotRequest.Merge(m, src)
+}
+func (m *CreateSnapshotRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateSnapshotRequest.Size(m)
+}
+func (m *CreateSnapshotRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateSnapshotRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateSnapshotRequest proto.InternalMessageInfo
+
+func (m *CreateSnapshotRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type Snapshot struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Indices              []string `protobuf:"bytes,2,rep,name=indices,proto3" json:"indices,omitempty"`
+	State                string   `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	StartTime            int64    `protobuf:"varint,4,opt,name=startTime,proto3" json:"startTime,omitempty"`
+	EndTime              int64    `protobuf:"varint,5,opt,name=endTime,proto3" json:"endTime,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Snapshot) Reset()         { *m = Snapshot{} }
+func (m *Snapshot) String() string { return proto.CompactTextString(m) }
+func (*Snapshot) ProtoMessage()    {}
+func (*Snapshot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{5}
+}
+
+func (m *Snapshot) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Snapshot.Unmarshal(m, b)
+}
+func (m *Snapshot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Snapshot.Marshal(b, m, deterministic)
+}
+func (m *Snapshot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Snapshot.Merge(m, src)
+}
+func (m *Snapshot) XXX_Size() int {
+	return xxx_messageInfo_Snapshot.Size(m)
+}
+func (m *Snapshot) XXX_DiscardUnknown() {
+	xxx_messageInfo_Snapshot.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Snapshot proto.InternalMessageInfo
+
+func (m *Snapshot) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Snapshot) GetIndices() []string {
+	if m != nil {
+		return m.Indices
+	}
+	return nil
+}
+
+func (m *Snapshot) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *Snapshot) GetStartTime() int64 {
+	if m != nil {
+		return m.StartTime
+	}
+	return 0
+}
+
+func (m *Snapshot) GetEndTime() int64 {
+	if m != nil {
+		return m.EndTime
+	}
+	return 0
+}
+
+type ListSnapshotsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListSnapshotsRequest) Reset()         { *m = ListSnapshotsRequest{} }
+func (m *ListSnapshotsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListSnapshotsRequest) ProtoMessage()    {}
+func (*ListSnapshotsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{6}
+}
+
+func (m *ListSnapshotsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSnapshotsRequest.Unmarshal(m, b)
+}
+func (m *ListSnapshotsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSnapshotsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListSnapshotsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSnapshotsRequest.Merge(m, src)
+}
+func (m *ListSnapshotsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListSnapshotsRequest.Size(m)
+}
+func (m *ListSnapshotsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSnapshotsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSnapshotsRequest proto.InternalMessageInfo
+
+type ListSnapshotsResponse struct {
+	Snapshots            []*Snapshot `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ListSnapshotsResponse) Reset()         { *m = ListSnapshotsResponse{} }
+func (m *ListSnapshotsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListSnapshotsResponse) ProtoMessage()    {}
+func (*ListSnapshotsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{7}
+}
+
+func (m *ListSnapshotsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSnapshotsResponse.Unmarshal(m, b)
+}
+func (m *ListSnapshotsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSnapshotsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListSnapshotsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSnapshotsResponse.Merge(m, src)
+}
+func (m *ListSnapshotsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListSnapshotsResponse.Size(m)
+}
+func (m *ListSnapshotsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSnapshotsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSnapshotsResponse proto.InternalMessageInfo
+
+func (m *ListSnapshotsResponse) GetSnapshots() []*Snapshot {
+	if m != nil {
+		return m.Snapshots
+	}
+	return nil
+}
+
+type RestoreSnapshotRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Alias                string   `protobuf:"bytes,2,opt,name=alias,proto3" json:"alias,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RestoreSnapshotRequest) Reset()         { *m = RestoreSnapshotRequest{} }
+func (m *RestoreSnapshotRequest) String() string { return proto.CompactTextString(m) }
+func (*RestoreSnapshotRequest) ProtoMessage()    {}
+func (*RestoreSnapshotRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{8}
+}
+
+func (m *RestoreSnapshotRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RestoreSnapshotRequest.Unmarshal(m, b)
+}
+func (m *RestoreSnapshotRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RestoreSnapshotRequest.Marshal(b, m, deterministic)
+}
+func (m *RestoreSnapshotRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RestoreSnapshotRequest.Merge(m, src)
+}
+func (m *RestoreSnapshotRequest) XXX_Size() int {
+	return xxx_messageInfo_RestoreSnapshotRequest.Size(m)
+}
+func (m *RestoreSnapshotRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RestoreSnapshotRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RestoreSnapshotRequest proto.InternalMessageInfo
+
+func (m *RestoreSnapshotRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *RestoreSnapshotRequest) GetAlias() string {
+	if m != nil {
+		return m.Alias
+	}
+	return ""
+}
+
+type RestoreSnapshotResponse struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Alias                string   `protobuf:"bytes,2,opt,name=alias,proto3" json:"alias,omitempty"`
+	Indices              []string `protobuf:"bytes,3,rep,name=indices,proto3" json:"indices,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RestoreSnapshotResponse) Reset()         { *m = RestoreSnapshotResponse{} }
+func (m *RestoreSnapshotResponse) String() string { return proto.CompactTextString(m) }
+func (*RestoreSnapshotResponse) ProtoMessage()    {}
+func (*RestoreSnapshotResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{9}
+}
+
+func (m *RestoreSnapshotResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RestoreSnapshotResponse.Unmarshal(m, b)
+}
+func (m *RestoreSnapshotResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RestoreSnapshotResponse.Marshal(b, m, deterministic)
+}
+func (m *RestoreSnapshotResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RestoreSnapshotResponse.Merge(m, src)
+}
+func (m *RestoreSnapshotResponse) XXX_Size() int {
+	return xxx_messageInfo_RestoreSnapshotResponse.Size(m)
+}
+func (m *RestoreSnapshotResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RestoreSnapshotResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RestoreSnapshotResponse proto.InternalMessageInfo
+
+func (m *RestoreSnapshotResponse) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *RestoreSnapshotResponse) GetAlias() string {
+	if m != nil {
+		return m.Alias
+	}
+	return ""
+}
+
+func (m *RestoreSnapshotResponse) GetIndices() []string {
+	if m != nil {
+		return m.Indices
+	}
+	return nil
+}
+
 type UpdateResponse struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -31,116 +513,616 @@ type UpdateResponse struct {
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *UpdateResponse) Reset()         { *m = UpdateResponse{} }
-func (m *UpdateResponse) String() string { return proto.CompactTextString(m) }
-func (*UpdateResponse) ProtoMessage()    {}
-func (*UpdateResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_453745cff914010e, []int{0}
+func (m *UpdateResponse) Reset()         { *m = UpdateResponse{} }
+func (m *UpdateResponse) String() string { return proto.CompactTextString(m) }
+func (*UpdateResponse) ProtoMessage()    {}
+func (*UpdateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{10}
+}
+
+func (m *UpdateResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateResponse.Unmarshal(m, b)
+}
+func (m *UpdateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateResponse.Marshal(b, m, deterministic)
+}
+func (m *UpdateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateResponse.Merge(m, src)
+}
+func (m *UpdateResponse) XXX_Size() int {
+	return xxx_messageInfo_UpdateResponse.Size(m)
+}
+func (m *UpdateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateResponse proto.InternalMessageInfo
+
+func (m *UpdateResponse) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type DeleteRequest struct {
+	Id      string  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Refresh Refresh `protobuf:"varint,2,opt,name=refresh,proto3,enum=search.Refresh" json:"refresh,omitempty"`
+	// ownerID is the owner of the file, optional, finds the file faster when files are routed by their owner.
+	OwnerID              string   `protobuf:"bytes,3,opt,name=ownerID,proto3" json:"ownerID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteRequest) Reset()         { *m = DeleteRequest{} }
+func (m *DeleteRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteRequest) ProtoMessage()    {}
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{11}
+}
+
+func (m *DeleteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteRequest.Unmarshal(m, b)
+}
+func (m *DeleteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteRequest.Merge(m, src)
+}
+func (m *DeleteRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteRequest.Size(m)
+}
+func (m *DeleteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteRequest proto.InternalMessageInfo
+
+func (m *DeleteRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *DeleteRequest) GetRefresh() Refresh {
+	if m != nil {
+		return m.Refresh
+	}
+	return Refresh_REFRESH_DEFAULT
+}
+
+func (m *DeleteRequest) GetOwnerID() string {
+	if m != nil {
+		return m.OwnerID
+	}
+	return ""
+}
+
+type DeleteResponse struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteResponse) Reset()         { *m = DeleteResponse{} }
+func (m *DeleteResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteResponse) ProtoMessage()    {}
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{12}
+}
+
+func (m *DeleteResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteResponse.Unmarshal(m, b)
+}
+func (m *DeleteResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteResponse.Marshal(b, m, deterministic)
+}
+func (m *DeleteResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteResponse.Merge(m, src)
+}
+func (m *DeleteResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteResponse.Size(m)
+}
+func (m *DeleteResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteResponse proto.InternalMessageInfo
+
+func (m *DeleteResponse) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type TrashRequest struct {
+	Id      string  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Refresh Refresh `protobuf:"varint,2,opt,name=refresh,proto3,enum=search.Refresh" json:"refresh,omitempty"`
+	// ownerID is the owner of the file, optional, finds the file faster when files are routed by their owner.
+	OwnerID              string   `protobuf:"bytes,3,opt,name=ownerID,proto3" json:"ownerID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TrashRequest) Reset()         { *m = TrashRequest{} }
+func (m *TrashRequest) String() string { return proto.CompactTextString(m) }
+func (*TrashRequest) ProtoMessage()    {}
+func (*TrashRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{13}
+}
+
+func (m *TrashRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TrashRequest.Unmarshal(m, b)
+}
+func (m *TrashRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TrashRequest.Marshal(b, m, deterministic)
+}
+func (m *TrashRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TrashRequest.Merge(m, src)
+}
+func (m *TrashRequest) XXX_Size() int {
+	return xxx_messageInfo_TrashRequest.Size(m)
+}
+func (m *TrashRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TrashRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TrashRequest proto.InternalMessageInfo
+
+func (m *TrashRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *TrashRequest) GetRefresh() Refresh {
+	if m != nil {
+		return m.Refresh
+	}
+	return Refresh_REFRESH_DEFAULT
+}
+
+func (m *TrashRequest) GetOwnerID() string {
+	if m != nil {
+		return m.OwnerID
+	}
+	return ""
+}
+
+type TrashResponse struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TrashResponse) Reset()         { *m = TrashResponse{} }
+func (m *TrashResponse) String() string { return proto.CompactTextString(m) }
+func (*TrashResponse) ProtoMessage()    {}
+func (*TrashResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{14}
+}
+
+func (m *TrashResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TrashResponse.Unmarshal(m, b)
+}
+func (m *TrashResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TrashResponse.Marshal(b, m, deterministic)
+}
+func (m *TrashResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TrashResponse.Merge(m, src)
+}
+func (m *TrashResponse) XXX_Size() int {
+	return xxx_messageInfo_TrashResponse.Size(m)
+}
+func (m *TrashResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TrashResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TrashResponse proto.InternalMessageInfo
+
+func (m *TrashResponse) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type RestoreRequest struct {
+	Id      string  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Refresh Refresh `protobuf:"varint,2,opt,name=refresh,proto3,enum=search.Refresh" json:"refresh,omitempty"`
+	// ownerID is the owner of the file, optional, finds the file faster when files are routed by their owner.
+	OwnerID              string   `protobuf:"bytes,3,opt,name=ownerID,proto3" json:"ownerID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RestoreRequest) Reset()         { *m = RestoreRequest{} }
+func (m *RestoreRequest) String() string { return proto.CompactTextString(m) }
+func (*RestoreRequest) ProtoMessage()    {}
+func (*RestoreRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{15}
 }
 
-func (m *UpdateResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_UpdateResponse.Unmarshal(m, b)
+func (m *RestoreRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RestoreRequest.Unmarshal(m, b)
 }
-func (m *UpdateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_UpdateResponse.Marshal(b, m, deterministic)
+func (m *RestoreRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RestoreRequest.Marshal(b, m, deterministic)
 }
-func (m *UpdateResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_UpdateResponse.Merge(m, src)
+func (m *RestoreRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RestoreRequest.Merge(m, src)
 }
-func (m *UpdateResponse) XXX_Size() int {
-	return xxx_messageInfo_UpdateResponse.Size(m)
+func (m *RestoreRequest) XXX_Size() int {
+	return xxx_messageInfo_RestoreRequest.Size(m)
 }
-func (m *UpdateResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_UpdateResponse.DiscardUnknown(m)
+func (m *RestoreRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RestoreRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_UpdateResponse proto.InternalMessageInfo
+var xxx_messageInfo_RestoreRequest proto.InternalMessageInfo
 
-func (m *UpdateResponse) GetId() string {
+func (m *RestoreRequest) GetId() string {
 	if m != nil {
 		return m.Id
 	}
 	return ""
 }
 
-type DeleteRequest struct {
+func (m *RestoreRequest) GetRefresh() Refresh {
+	if m != nil {
+		return m.Refresh
+	}
+	return Refresh_REFRESH_DEFAULT
+}
+
+func (m *RestoreRequest) GetOwnerID() string {
+	if m != nil {
+		return m.OwnerID
+	}
+	return ""
+}
+
+type RestoreResponse struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *DeleteRequest) Reset()         { *m = DeleteRequest{} }
-func (m *DeleteRequest) String() string { return proto.CompactTextString(m) }
-func (*DeleteRequest) ProtoMessage()    {}
-func (*DeleteRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_453745cff914010e, []int{1}
+func (m *RestoreResponse) Reset()         { *m = RestoreResponse{} }
+func (m *RestoreResponse) String() string { return proto.CompactTextString(m) }
+func (*RestoreResponse) ProtoMessage()    {}
+func (*RestoreResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{16}
 }
 
-func (m *DeleteRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_DeleteRequest.Unmarshal(m, b)
+func (m *RestoreResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RestoreResponse.Unmarshal(m, b)
 }
-func (m *DeleteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_DeleteRequest.Marshal(b, m, deterministic)
+func (m *RestoreResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RestoreResponse.Marshal(b, m, deterministic)
 }
-func (m *DeleteRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_DeleteRequest.Merge(m, src)
+func (m *RestoreResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RestoreResponse.Merge(m, src)
 }
-func (m *DeleteRequest) XXX_Size() int {
-	return xxx_messageInfo_DeleteRequest.Size(m)
+func (m *RestoreResponse) XXX_Size() int {
+	return xxx_messageInfo_RestoreResponse.Size(m)
 }
-func (m *DeleteRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_DeleteRequest.DiscardUnknown(m)
+func (m *RestoreResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RestoreResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_DeleteRequest proto.InternalMessageInfo
+var xxx_messageInfo_RestoreResponse proto.InternalMessageInfo
 
-func (m *DeleteRequest) GetId() string {
+func (m *RestoreResponse) GetId() string {
 	if m != nil {
 		return m.Id
 	}
 	return ""
 }
 
-type DeleteResponse struct {
+type Permission struct {
+	UserID               string   `protobuf:"bytes,1,opt,name=userID,proto3" json:"userID,omitempty"`
+	GroupID              string   `protobuf:"bytes,2,opt,name=groupID,proto3" json:"groupID,omitempty"`
+	Role                 string   `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Permission) Reset()         { *m = Permission{} }
+func (m *Permission) String() string { return proto.CompactTextString(m) }
+func (*Permission) ProtoMessage()    {}
+func (*Permission) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{17}
+}
+
+func (m *Permission) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Permission.Unmarshal(m, b)
+}
+func (m *Permission) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Permission.Marshal(b, m, deterministic)
+}
+func (m *Permission) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Permission.Merge(m, src)
+}
+func (m *Permission) XXX_Size() int {
+	return xxx_messageInfo_Permission.Size(m)
+}
+func (m *Permission) XXX_DiscardUnknown() {
+	xxx_messageInfo_Permission.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Permission proto.InternalMessageInfo
+
+func (m *Permission) GetUserID() string {
+	if m != nil {
+		return m.UserID
+	}
+	return ""
+}
+
+func (m *Permission) GetGroupID() string {
+	if m != nil {
+		return m.GroupID
+	}
+	return ""
+}
+
+func (m *Permission) GetRole() string {
+	if m != nil {
+		return m.Role
+	}
+	return ""
+}
+
+type UpdatePermissionsRequest struct {
+	Id          string        `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Permissions []*Permission `protobuf:"bytes,2,rep,name=permissions,proto3" json:"permissions,omitempty"`
+	Refresh     Refresh       `protobuf:"varint,3,opt,name=refresh,proto3,enum=search.Refresh" json:"refresh,omitempty"`
+	// ownerID is the owner of the file, optional, finds the file faster when files are routed by their owner.
+	OwnerID              string   `protobuf:"bytes,4,opt,name=ownerID,proto3" json:"ownerID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdatePermissionsRequest) Reset()         { *m = UpdatePermissionsRequest{} }
+func (m *UpdatePermissionsRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdatePermissionsRequest) ProtoMessage()    {}
+func (*UpdatePermissionsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{18}
+}
+
+func (m *UpdatePermissionsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdatePermissionsRequest.Unmarshal(m, b)
+}
+func (m *UpdatePermissionsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdatePermissionsRequest.Marshal(b, m, deterministic)
+}
+func (m *UpdatePermissionsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdatePermissionsRequest.Merge(m, src)
+}
+func (m *UpdatePermissionsRequest) XXX_Size() int {
+	return xxx_messageInfo_UpdatePermissionsRequest.Size(m)
+}
+func (m *UpdatePermissionsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdatePermissionsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdatePermissionsRequest proto.InternalMessageInfo
+
+func (m *UpdatePermissionsRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *UpdatePermissionsRequest) GetPermissions() []*Permission {
+	if m != nil {
+		return m.Permissions
+	}
+	return nil
+}
+
+func (m *UpdatePermissionsRequest) GetRefresh() Refresh {
+	if m != nil {
+		return m.Refresh
+	}
+	return Refresh_REFRESH_DEFAULT
+}
+
+func (m *UpdatePermissionsRequest) GetOwnerID() string {
+	if m != nil {
+		return m.OwnerID
+	}
+	return ""
+}
+
+type UpdatePermissionsResponse struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *DeleteResponse) Reset()         { *m = DeleteResponse{} }
-func (m *DeleteResponse) String() string { return proto.CompactTextString(m) }
-func (*DeleteResponse) ProtoMessage()    {}
-func (*DeleteResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_453745cff914010e, []int{2}
+func (m *UpdatePermissionsResponse) Reset()         { *m = UpdatePermissionsResponse{} }
+func (m *UpdatePermissionsResponse) String() string { return proto.CompactTextString(m) }
+func (*UpdatePermissionsResponse) ProtoMessage()    {}
+func (*UpdatePermissionsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{19}
 }
 
-func (m *DeleteResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_DeleteResponse.Unmarshal(m, b)
+func (m *UpdatePermissionsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdatePermissionsResponse.Unmarshal(m, b)
 }
-func (m *DeleteResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_DeleteResponse.Marshal(b, m, deterministic)
+func (m *UpdatePermissionsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdatePermissionsResponse.Marshal(b, m, deterministic)
 }
-func (m *DeleteResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_DeleteResponse.Merge(m, src)
+func (m *UpdatePermissionsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdatePermissionsResponse.Merge(m, src)
 }
-func (m *DeleteResponse) XXX_Size() int {
-	return xxx_messageInfo_DeleteResponse.Size(m)
+func (m *UpdatePermissionsResponse) XXX_Size() int {
+	return xxx_messageInfo_UpdatePermissionsResponse.Size(m)
 }
-func (m *DeleteResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_DeleteResponse.DiscardUnknown(m)
+func (m *UpdatePermissionsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdatePermissionsResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_DeleteResponse proto.InternalMessageInfo
+var xxx_messageInfo_UpdatePermissionsResponse proto.InternalMessageInfo
 
-func (m *DeleteResponse) GetId() string {
+func (m *UpdatePermissionsResponse) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type GetFileRequest struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// ownerID is the owner of the file, optional, finds the file faster when files are routed by their owner.
+	OwnerID              string   `protobuf:"bytes,2,opt,name=ownerID,proto3" json:"ownerID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetFileRequest) Reset()         { *m = GetFileRequest{} }
+func (m *GetFileRequest) String() string { return proto.CompactTextString(m) }
+func (*GetFileRequest) ProtoMessage()    {}
+func (*GetFileRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{20}
+}
+
+func (m *GetFileRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetFileRequest.Unmarshal(m, b)
+}
+func (m *GetFileRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetFileRequest.Marshal(b, m, deterministic)
+}
+func (m *GetFileRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetFileRequest.Merge(m, src)
+}
+func (m *GetFileRequest) XXX_Size() int {
+	return xxx_messageInfo_GetFileRequest.Size(m)
+}
+func (m *GetFileRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetFileRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetFileRequest proto.InternalMessageInfo
+
+func (m *GetFileRequest) GetId() string {
 	if m != nil {
 		return m.Id
 	}
 	return ""
 }
 
+func (m *GetFileRequest) GetOwnerID() string {
+	if m != nil {
+		return m.OwnerID
+	}
+	return ""
+}
+
+type ExistsRequest struct {
+	Ids []string `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	// ownerID is the owner of the files, optional, finds the files faster when files are routed by their owner.
+	OwnerID              string   `protobuf:"bytes,2,opt,name=ownerID,proto3" json:"ownerID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExistsRequest) Reset()         { *m = ExistsRequest{} }
+func (m *ExistsRequest) String() string { return proto.CompactTextString(m) }
+func (*ExistsRequest) ProtoMessage()    {}
+func (*ExistsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{21}
+}
+
+func (m *ExistsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExistsRequest.Unmarshal(m, b)
+}
+func (m *ExistsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExistsRequest.Marshal(b, m, deterministic)
+}
+func (m *ExistsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExistsRequest.Merge(m, src)
+}
+func (m *ExistsRequest) XXX_Size() int {
+	return xxx_messageInfo_ExistsRequest.Size(m)
+}
+func (m *ExistsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExistsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExistsRequest proto.InternalMessageInfo
+
+func (m *ExistsRequest) GetIds() []string {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+func (m *ExistsRequest) GetOwnerID() string {
+	if m != nil {
+		return m.OwnerID
+	}
+	return ""
+}
+
+type ExistsResponse struct {
+	Ids                  []string `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExistsResponse) Reset()         { *m = ExistsResponse{} }
+func (m *ExistsResponse) String() string { return proto.CompactTextString(m) }
+func (*ExistsResponse) ProtoMessage()    {}
+func (*ExistsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_453745cff914010e, []int{22}
+}
+
+func (m *ExistsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExistsResponse.Unmarshal(m, b)
+}
+func (m *ExistsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExistsResponse.Marshal(b, m, deterministic)
+}
+func (m *ExistsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExistsResponse.Merge(m, src)
+}
+func (m *ExistsResponse) XXX_Size() int {
+	return xxx_messageInfo_ExistsResponse.Size(m)
+}
+func (m *ExistsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExistsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExistsResponse proto.InternalMessageInfo
+
+func (m *ExistsResponse) GetIds() []string {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
 type File struct {
 	Id          string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Key         string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
@@ -152,21 +1134,29 @@ type File struct {
 	// Types that are valid to be assigned to FileOrId:
 	//	*File_Parent
 	//	*File_ParentObject
-	FileOrId             isFile_FileOrId `protobuf_oneof:"fileOrId"`
-	Bucket               string          `protobuf:"bytes,10,opt,name=bucket,proto3" json:"bucket,omitempty"`
-	CreatedAt            int64           `protobuf:"varint,11,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
-	UpdatedAt            int64           `protobuf:"varint,12,opt,name=updatedAt,proto3" json:"updatedAt,omitempty"`
-	Children             []*File         `protobuf:"bytes,13,rep,name=children,proto3" json:"children,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
-	XXX_unrecognized     []byte          `json:"-"`
-	XXX_sizecache        int32           `json:"-"`
+	FileOrId    isFile_FileOrId   `protobuf_oneof:"fileOrId"`
+	Bucket      string            `protobuf:"bytes,10,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	CreatedAt   int64             `protobuf:"varint,11,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	UpdatedAt   int64             `protobuf:"varint,12,opt,name=updatedAt,proto3" json:"updatedAt,omitempty"`
+	Children    []*File           `protobuf:"bytes,13,rep,name=children,proto3" json:"children,omitempty"`
+	Trashed     bool              `protobuf:"varint,14,opt,name=trashed,proto3" json:"trashed,omitempty"`
+	DeletedAt   int64             `protobuf:"varint,15,opt,name=deletedAt,proto3" json:"deletedAt,omitempty"`
+	Permissions []*Permission     `protobuf:"bytes,16,rep,name=permissions,proto3" json:"permissions,omitempty"`
+	Tags        []string          `protobuf:"bytes,17,rep,name=tags,proto3" json:"tags,omitempty"`
+	Metadata    map[string]string `protobuf:"bytes,18,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// refresh is an option of the CreateFile and Update requests rather than a field of the file,
+	// it's never stored and is always REFRESH_DEFAULT in the files returned by GetFile.
+	Refresh              Refresh  `protobuf:"varint,19,opt,name=refresh,proto3,enum=search.Refresh" json:"refresh,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *File) Reset()         { *m = File{} }
 func (m *File) String() string { return proto.CompactTextString(m) }
 func (*File) ProtoMessage()    {}
 func (*File) Descriptor() ([]byte, []int) {
-	return fileDescriptor_453745cff914010e, []int{3}
+	return fileDescriptor_453745cff914010e, []int{23}
 }
 
 func (m *File) XXX_Unmarshal(b []byte) error {
@@ -301,6 +1291,48 @@ func (m *File) GetChildren() []*File {
 	return nil
 }
 
+func (m *File) GetTrashed() bool {
+	if m != nil {
+		return m.Trashed
+	}
+	return false
+}
+
+func (m *File) GetDeletedAt() int64 {
+	if m != nil {
+		return m.DeletedAt
+	}
+	return 0
+}
+
+func (m *File) GetPermissions() []*Permission {
+	if m != nil {
+		return m.Permissions
+	}
+	return nil
+}
+
+func (m *File) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *File) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *File) GetRefresh() Refresh {
+	if m != nil {
+		return m.Refresh
+	}
+	return Refresh_REFRESH_DEFAULT
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*File) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
@@ -320,7 +1352,7 @@ func (m *CreateFileResponse) Reset()         { *m = CreateFileResponse{} }
 func (m *CreateFileResponse) String() string { return proto.CompactTextString(m) }
 func (*CreateFileResponse) ProtoMessage()    {}
 func (*CreateFileResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_453745cff914010e, []int{4}
+	return fileDescriptor_453745cff914010e, []int{24}
 }
 
 func (m *CreateFileResponse) XXX_Unmarshal(b []byte) error {
@@ -349,7 +1381,15 @@ func (m *CreateFileResponse) GetId() string {
 }
 
 type SearchRequest struct {
-	Term                 string   `protobuf:"bytes,1,opt,name=term,proto3" json:"term,omitempty"`
+	Term           string   `protobuf:"bytes,1,opt,name=term,proto3" json:"term,omitempty"`
+	IncludeTrashed bool     `protobuf:"varint,2,opt,name=includeTrashed,proto3" json:"includeTrashed,omitempty"`
+	OnlyTrashed    bool     `protobuf:"varint,3,opt,name=onlyTrashed,proto3" json:"onlyTrashed,omitempty"`
+	UserID         string   `protobuf:"bytes,4,opt,name=userID,proto3" json:"userID,omitempty"`
+	GroupIDs       []string `protobuf:"bytes,5,rep,name=groupIDs,proto3" json:"groupIDs,omitempty"`
+	// filters narrow the results, each either `tag:<tag>` or `metadata.<key>=<value>`.
+	Filters []string `protobuf:"bytes,6,rep,name=filters,proto3" json:"filters,omitempty"`
+	// ownerID restricts the results to the files owned by ownerID.
+	OwnerID              string   `protobuf:"bytes,7,opt,name=ownerID,proto3" json:"ownerID,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -359,7 +1399,7 @@ func (m *SearchRequest) Reset()         { *m = SearchRequest{} }
 func (m *SearchRequest) String() string { return proto.CompactTextString(m) }
 func (*SearchRequest) ProtoMessage()    {}
 func (*SearchRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_453745cff914010e, []int{5}
+	return fileDescriptor_453745cff914010e, []int{25}
 }
 
 func (m *SearchRequest) XXX_Unmarshal(b []byte) error {
@@ -378,11 +1418,53 @@ func (m *SearchRequest) XXX_DiscardUnknown() {
 	xxx_messageInfo_SearchRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_SearchRequest proto.InternalMessageInfo
+var xxx_messageInfo_SearchRequest proto.InternalMessageInfo
+
+func (m *SearchRequest) GetTerm() string {
+	if m != nil {
+		return m.Term
+	}
+	return ""
+}
+
+func (m *SearchRequest) GetIncludeTrashed() bool {
+	if m != nil {
+		return m.IncludeTrashed
+	}
+	return false
+}
+
+func (m *SearchRequest) GetOnlyTrashed() bool {
+	if m != nil {
+		return m.OnlyTrashed
+	}
+	return false
+}
+
+func (m *SearchRequest) GetUserID() string {
+	if m != nil {
+		return m.UserID
+	}
+	return ""
+}
+
+func (m *SearchRequest) GetGroupIDs() []string {
+	if m != nil {
+		return m.GroupIDs
+	}
+	return nil
+}
 
-func (m *SearchRequest) GetTerm() string {
+func (m *SearchRequest) GetFilters() []string {
 	if m != nil {
-		return m.Term
+		return m.Filters
+	}
+	return nil
+}
+
+func (m *SearchRequest) GetOwnerID() string {
+	if m != nil {
+		return m.OwnerID
 	}
 	return ""
 }
@@ -398,7 +1480,7 @@ func (m *SearchResponse) Reset()         { *m = SearchResponse{} }
 func (m *SearchResponse) String() string { return proto.CompactTextString(m) }
 func (*SearchResponse) ProtoMessage()    {}
 func (*SearchResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_453745cff914010e, []int{6}
+	return fileDescriptor_453745cff914010e, []int{26}
 }
 
 func (m *SearchResponse) XXX_Unmarshal(b []byte) error {
@@ -427,10 +1509,32 @@ func (m *SearchResponse) GetIds() []string {
 }
 
 func init() {
+	proto.RegisterEnum("search.Refresh", Refresh_name, Refresh_value)
+	proto.RegisterType((*ReindexRequest)(nil), "search.ReindexRequest")
+	proto.RegisterType((*ReindexResponse)(nil), "search.ReindexResponse")
+	proto.RegisterType((*MigrateRequest)(nil), "search.MigrateRequest")
+	proto.RegisterType((*MigrateResponse)(nil), "search.MigrateResponse")
+	proto.RegisterType((*CreateSnapshotRequest)(nil), "search.CreateSnapshotRequest")
+	proto.RegisterType((*Snapshot)(nil), "search.Snapshot")
+	proto.RegisterType((*ListSnapshotsRequest)(nil), "search.ListSnapshotsRequest")
+	proto.RegisterType((*ListSnapshotsResponse)(nil), "search.ListSnapshotsResponse")
+	proto.RegisterType((*RestoreSnapshotRequest)(nil), "search.RestoreSnapshotRequest")
+	proto.RegisterType((*RestoreSnapshotResponse)(nil), "search.RestoreSnapshotResponse")
 	proto.RegisterType((*UpdateResponse)(nil), "search.UpdateResponse")
 	proto.RegisterType((*DeleteRequest)(nil), "search.DeleteRequest")
 	proto.RegisterType((*DeleteResponse)(nil), "search.DeleteResponse")
+	proto.RegisterType((*TrashRequest)(nil), "search.TrashRequest")
+	proto.RegisterType((*TrashResponse)(nil), "search.TrashResponse")
+	proto.RegisterType((*RestoreRequest)(nil), "search.RestoreRequest")
+	proto.RegisterType((*RestoreResponse)(nil), "search.RestoreResponse")
+	proto.RegisterType((*Permission)(nil), "search.Permission")
+	proto.RegisterType((*UpdatePermissionsRequest)(nil), "search.UpdatePermissionsRequest")
+	proto.RegisterType((*UpdatePermissionsResponse)(nil), "search.UpdatePermissionsResponse")
+	proto.RegisterType((*GetFileRequest)(nil), "search.GetFileRequest")
+	proto.RegisterType((*ExistsRequest)(nil), "search.ExistsRequest")
+	proto.RegisterType((*ExistsResponse)(nil), "search.ExistsResponse")
 	proto.RegisterType((*File)(nil), "search.File")
+	proto.RegisterMapType((map[string]string)(nil), "search.File.MetadataEntry")
 	proto.RegisterType((*CreateFileResponse)(nil), "search.CreateFileResponse")
 	proto.RegisterType((*SearchRequest)(nil), "search.SearchRequest")
 	proto.RegisterType((*SearchResponse)(nil), "search.SearchResponse")
@@ -439,34 +1543,84 @@ func init() {
 func init() { proto.RegisterFile("search.proto", fileDescriptor_453745cff914010e) }
 
 var fileDescriptor_453745cff914010e = []byte{
-	// 420 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x53, 0x4d, 0x6f, 0xd3, 0x40,
-	0x10, 0xb5, 0xe3, 0xe0, 0x26, 0x13, 0xc7, 0xaa, 0x46, 0xa2, 0x5a, 0x45, 0x48, 0xac, 0x16, 0x0e,
-	0x3e, 0x55, 0x28, 0x5c, 0xe0, 0x08, 0x54, 0xa8, 0x3d, 0x55, 0x32, 0xe2, 0x07, 0x38, 0xde, 0x41,
-	0x5d, 0xea, 0xda, 0x66, 0x77, 0x23, 0x54, 0x7e, 0x32, 0xbf, 0x80, 0x23, 0xda, 0xb5, 0x1d, 0xd7,
-	0x89, 0x7a, 0x7b, 0xf3, 0xde, 0x9b, 0x0f, 0x7b, 0x66, 0x21, 0x31, 0x54, 0xe8, 0xf2, 0xee, 0xb2,
-	0xd5, 0x8d, 0x6d, 0x30, 0xee, 0x22, 0xc1, 0x21, 0xfd, 0xde, 0xca, 0xc2, 0x52, 0x4e, 0xa6, 0x6d,
-	0x6a, 0x43, 0x98, 0xc2, 0x4c, 0x49, 0x16, 0xf2, 0x30, 0x5b, 0xe6, 0x33, 0x25, 0xc5, 0x6b, 0x58,
-	0x5f, 0x51, 0x45, 0xce, 0xf1, 0x6b, 0x4f, 0xc6, 0x9e, 0x18, 0x38, 0xa4, 0x83, 0xe1, 0x99, 0x12,
-	0xff, 0x66, 0x30, 0xff, 0xaa, 0xaa, 0x13, 0x01, 0xcf, 0x21, 0xba, 0xa7, 0x47, 0x36, 0xf3, 0x84,
-	0x83, 0x88, 0x30, 0xaf, 0x8b, 0x07, 0x62, 0x91, 0xa7, 0x3c, 0x76, 0x9c, 0x7d, 0x6c, 0x89, 0xcd,
-	0x3b, 0xce, 0x61, 0xe4, 0xb0, 0x92, 0x64, 0x4a, 0xad, 0x5a, 0xab, 0x9a, 0x9a, 0xbd, 0xf0, 0xd2,
-	0x53, 0x0a, 0x19, 0x9c, 0x35, 0xbf, 0x6b, 0xd2, 0x37, 0x57, 0x2c, 0xf6, 0xea, 0x10, 0xba, 0x7a,
-	0x46, 0xfd, 0x21, 0x76, 0xc6, 0xc3, 0x2c, 0xca, 0x3d, 0x46, 0x06, 0x71, 0x5b, 0x68, 0xaa, 0x2d,
-	0x5b, 0x38, 0xf3, 0x75, 0x90, 0xf7, 0x31, 0x6e, 0x21, 0xe9, 0xd0, 0xed, 0xee, 0x27, 0x95, 0x96,
-	0x2d, 0x79, 0x98, 0xad, 0xb6, 0xc9, 0x65, 0xff, 0x3b, 0xdd, 0x77, 0x5d, 0x07, 0xf9, 0xc4, 0x83,
-	0x17, 0x10, 0xef, 0xf6, 0xe5, 0x3d, 0x59, 0x06, 0xbe, 0x75, 0x1f, 0xe1, 0x2b, 0x58, 0x96, 0x9a,
-	0x0a, 0x4b, 0xf2, 0x93, 0x65, 0x2b, 0xdf, 0x7e, 0x24, 0x9c, 0xba, 0xf7, 0xbb, 0x70, 0x6a, 0xd2,
-	0xa9, 0x07, 0x02, 0x33, 0x58, 0x94, 0x77, 0xaa, 0x92, 0x9a, 0x6a, 0xb6, 0xe6, 0xd1, 0xf1, 0x0c,
-	0xf9, 0x41, 0xfd, 0x0c, 0xb0, 0xf8, 0xa1, 0x2a, 0xba, 0xd5, 0x37, 0x52, 0xbc, 0x05, 0xfc, 0xe2,
-	0x1b, 0x78, 0xcf, 0x73, 0x0b, 0x7a, 0x03, 0xeb, 0x6f, 0xbe, 0xd4, 0xb0, 0x63, 0xf7, 0xcb, 0x49,
-	0x3f, 0xf4, 0x16, 0x8f, 0x85, 0x80, 0x74, 0x30, 0xf5, 0x65, 0xce, 0x21, 0x52, 0xd2, 0xb0, 0x90,
-	0x47, 0x6e, 0x7d, 0x4a, 0x9a, 0xed, 0xdf, 0x10, 0xfa, 0xcb, 0xc2, 0x0f, 0x00, 0x63, 0x67, 0x9c,
-	0xcc, 0xba, 0xd9, 0x0c, 0xd1, 0xe9, 0x6c, 0x22, 0xc0, 0x8f, 0x10, 0x77, 0x8d, 0xf0, 0xe5, 0xe0,
-	0x9b, 0x4c, 0xb7, 0xb9, 0x38, 0xa6, 0x9f, 0xa6, 0x76, 0xb7, 0x38, 0xa6, 0x4e, 0x8e, 0x77, 0x4c,
-	0x9d, 0x9e, 0xac, 0x08, 0xf0, 0x1d, 0xc4, 0xdd, 0x4b, 0x38, 0x9a, 0xf5, 0x90, 0x31, 0x7d, 0x27,
-	0x22, 0xd8, 0xc5, 0xfe, 0x29, 0xbd, 0xff, 0x1f, 0x00, 0x00, 0xff, 0xff, 0x5b, 0x21, 0x06, 0xd8,
-	0x5a, 0x03, 0x00, 0x00,
+	// 1224 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x57, 0xeb, 0x6e, 0x1b, 0xc5,
+	0x17, 0xf7, 0x7a, 0x7d, 0x3d, 0xbe, 0x76, 0x9a, 0x38, 0xfb, 0x5f, 0xb5, 0xff, 0xba, 0x2b, 0x84,
+	0x0c, 0x95, 0x22, 0x30, 0xa8, 0x2a, 0x2d, 0x5f, 0xd2, 0xc6, 0x69, 0x2d, 0x35, 0x0d, 0x6c, 0x5d,
+	0x90, 0x90, 0xaa, 0x6a, 0xe3, 0x9d, 0x24, 0x4b, 0xed, 0x5d, 0x33, 0x33, 0x86, 0x86, 0x07, 0xe0,
+	0x21, 0xf8, 0xce, 0x17, 0xde, 0x87, 0xd7, 0xe0, 0x19, 0xd0, 0x5c, 0xf7, 0xe2, 0x38, 0xca, 0x97,
+	0x7e, 0x9b, 0x73, 0x99, 0x73, 0x7e, 0x73, 0x2e, 0x73, 0x66, 0xa0, 0x4d, 0x71, 0x40, 0xe6, 0x17,
+	0xfb, 0x2b, 0x92, 0xb0, 0x04, 0xd5, 0x24, 0xe5, 0xf5, 0xa1, 0xeb, 0xe3, 0x28, 0x0e, 0xf1, 0x07,
+	0x1f, 0xff, 0xb2, 0xc6, 0x94, 0x79, 0x01, 0xf4, 0x0c, 0x87, 0xae, 0x92, 0x98, 0x62, 0x34, 0x80,
+	0x1a, 0x4d, 0xd6, 0x64, 0x8e, 0x1d, 0x6b, 0x68, 0x8d, 0x9a, 0xbe, 0xa2, 0xd0, 0x10, 0x5a, 0x21,
+	0xa6, 0x2c, 0x8a, 0x03, 0x16, 0x25, 0xb1, 0x53, 0x16, 0xc2, 0x2c, 0x0b, 0xed, 0x40, 0x95, 0x25,
+	0x2c, 0x58, 0x38, 0xf6, 0xd0, 0x1a, 0xd9, 0xbe, 0x24, 0xb8, 0xd3, 0xe3, 0xe8, 0x9c, 0x04, 0x0c,
+	0x6b, 0xa7, 0xdf, 0x43, 0xcf, 0x70, 0x94, 0xd3, 0x21, 0xb4, 0xce, 0x48, 0xb2, 0xfc, 0x01, 0x13,
+	0xca, 0x8d, 0x5b, 0xc2, 0x40, 0x96, 0x85, 0xee, 0x40, 0x93, 0x25, 0x5a, 0x5e, 0x16, 0xf2, 0x94,
+	0xe1, 0x3d, 0x80, 0xdd, 0x67, 0x04, 0x07, 0x0c, 0xbf, 0x8e, 0x83, 0x15, 0xbd, 0x48, 0x98, 0xf2,
+	0x85, 0x10, 0x54, 0xe2, 0x60, 0xa9, 0xcf, 0x22, 0xd6, 0xde, 0x1f, 0x16, 0x34, 0xb4, 0xde, 0x55,
+	0x0a, 0xc8, 0x81, 0x7a, 0x14, 0x87, 0xd1, 0x1c, 0x53, 0xa7, 0x3c, 0xb4, 0x47, 0x4d, 0x5f, 0x93,
+	0xfc, 0x88, 0x94, 0x05, 0x0c, 0x8b, 0x23, 0x36, 0x7d, 0x49, 0x70, 0x6c, 0x94, 0x05, 0x84, 0xcd,
+	0xa2, 0x25, 0x76, 0x2a, 0x12, 0x9b, 0x61, 0x70, 0x6b, 0x38, 0x0e, 0x85, 0xac, 0x2a, 0x64, 0x9a,
+	0xf4, 0x06, 0xb0, 0xf3, 0x32, 0xa2, 0x4c, 0x63, 0xa1, 0x3a, 0x40, 0xcf, 0x61, 0xb7, 0xc0, 0x57,
+	0x61, 0xda, 0x87, 0x26, 0xd5, 0x4c, 0xc7, 0x1a, 0xda, 0xa3, 0xd6, 0xb8, 0xbf, 0xaf, 0x52, 0x6d,
+	0x4e, 0x9e, 0xaa, 0x78, 0x4f, 0x61, 0xe0, 0x63, 0xca, 0x12, 0x72, 0x93, 0xb8, 0xf0, 0xc3, 0x05,
+	0x8b, 0x28, 0xa0, 0x2a, 0xb7, 0x92, 0xf0, 0xde, 0xc2, 0xde, 0x86, 0x0d, 0x05, 0xe7, 0xc6, 0x46,
+	0xb2, 0x11, 0xb5, 0x73, 0x11, 0xf5, 0x86, 0xd0, 0x7d, 0xb3, 0x0a, 0xb3, 0xb5, 0xd0, 0x85, 0x72,
+	0x14, 0x2a, 0x9b, 0xe5, 0x28, 0xf4, 0x42, 0xe8, 0x1c, 0xe2, 0x05, 0x36, 0xf5, 0x53, 0x54, 0x40,
+	0x9f, 0x41, 0x9d, 0xe0, 0x33, 0x82, 0xe9, 0x85, 0x70, 0xda, 0x1d, 0xf7, 0x74, 0x4c, 0x7c, 0xc9,
+	0xf6, 0xb5, 0x9c, 0xe3, 0x48, 0x7e, 0x8b, 0x31, 0x99, 0x1e, 0xaa, 0x0c, 0x6a, 0x92, 0xe3, 0xd0,
+	0x5e, 0xb6, 0xe0, 0x98, 0x43, 0x7b, 0x46, 0x02, 0x7a, 0xf1, 0x51, 0x61, 0xdc, 0x83, 0x8e, 0x72,
+	0xb2, 0x05, 0x05, 0x86, 0xae, 0x4a, 0xc7, 0x47, 0xc5, 0x71, 0x1f, 0x7a, 0xc6, 0xcd, 0x16, 0x24,
+	0x3e, 0xc0, 0x77, 0x98, 0x2c, 0x23, 0x2a, 0xfa, 0x73, 0x00, 0xb5, 0x35, 0x15, 0x96, 0xd4, 0xb5,
+	0x21, 0x29, 0xee, 0xe2, 0x9c, 0x24, 0xeb, 0xd5, 0xf4, 0x50, 0x55, 0x84, 0x26, 0x79, 0xf5, 0x90,
+	0x64, 0xa1, 0x5b, 0x49, 0xac, 0xbd, 0xbf, 0x2c, 0x70, 0x64, 0x39, 0xa4, 0xa6, 0xe9, 0xb6, 0x83,
+	0x7e, 0x0d, 0xad, 0x55, 0xaa, 0x25, 0x5a, 0xb5, 0x35, 0x46, 0xfa, 0xb0, 0xa9, 0x01, 0x3f, 0xab,
+	0x96, 0x0d, 0x8f, 0x7d, 0xf3, 0xf0, 0x54, 0xf2, 0xe1, 0x79, 0x00, 0xff, 0xbb, 0x02, 0xe6, 0x96,
+	0x40, 0x3d, 0x86, 0xee, 0x73, 0xcc, 0x8e, 0xa2, 0xc5, 0xd6, 0x94, 0x65, 0x1c, 0x95, 0xf3, 0x8e,
+	0x9e, 0x40, 0x67, 0xf2, 0x21, 0xa2, 0xe6, 0x6e, 0x40, 0x7d, 0xb0, 0xa3, 0x50, 0x36, 0x7f, 0xd3,
+	0xe7, 0xcb, 0x6b, 0x36, 0x7b, 0xd0, 0xd5, 0x9b, 0x15, 0xb4, 0x8d, 0xdd, 0xde, 0x9f, 0x55, 0xa8,
+	0x70, 0x68, 0x1b, 0x98, 0xfa, 0x60, 0xbf, 0xc7, 0x97, 0xca, 0x24, 0x5f, 0x9a, 0x76, 0xb7, 0x33,
+	0xed, 0x8e, 0xa0, 0xc2, 0x2e, 0x57, 0x58, 0xc5, 0x47, 0xac, 0xd5, 0xa4, 0x98, 0x93, 0x68, 0x25,
+	0x26, 0x45, 0xd5, 0x4c, 0x0a, 0xcd, 0xca, 0x42, 0xae, 0xe5, 0x20, 0x73, 0x7b, 0x34, 0xfa, 0x1d,
+	0x3b, 0x75, 0x71, 0x53, 0x8a, 0x35, 0x72, 0xa0, 0xb6, 0x0a, 0x08, 0x8e, 0x99, 0xd3, 0xe0, 0xca,
+	0x2f, 0x4a, 0xbe, 0xa2, 0xd1, 0x18, 0xda, 0x72, 0x75, 0x72, 0xfa, 0x33, 0x9e, 0x33, 0xa7, 0x39,
+	0xb4, 0x46, 0xad, 0x71, 0x5b, 0x27, 0x94, 0x9f, 0xeb, 0x45, 0xc9, 0xcf, 0xe9, 0xf0, 0x42, 0x3d,
+	0x5d, 0xcf, 0xdf, 0x63, 0xe6, 0x80, 0x2c, 0x54, 0x49, 0xf1, 0x4b, 0x7c, 0x2e, 0x46, 0x48, 0x78,
+	0xc0, 0x9c, 0x96, 0xbc, 0xc4, 0x0d, 0x83, 0x4b, 0xd7, 0xab, 0x50, 0x49, 0xdb, 0x52, 0x6a, 0x18,
+	0x68, 0x04, 0x8d, 0xf9, 0x45, 0xb4, 0x08, 0x09, 0x8e, 0x9d, 0xce, 0xd0, 0x2e, 0x62, 0xf0, 0x8d,
+	0x94, 0x9f, 0x9c, 0xf1, 0xfe, 0xc6, 0xa1, 0xd3, 0x1d, 0x5a, 0xa3, 0x86, 0xaf, 0x49, 0xee, 0x21,
+	0x14, 0x17, 0x10, 0xf7, 0xd0, 0x93, 0x1e, 0x0c, 0xa3, 0x58, 0xeb, 0xfd, 0x9b, 0xd5, 0x3a, 0xcf,
+	0x4e, 0x70, 0x4e, 0x9d, 0x5b, 0x22, 0xdf, 0x62, 0x8d, 0x1e, 0x42, 0x63, 0x89, 0x59, 0x10, 0x06,
+	0x2c, 0x70, 0x90, 0x30, 0xe3, 0x66, 0xb1, 0xee, 0x1f, 0x2b, 0xe1, 0x24, 0x66, 0xe4, 0xd2, 0x37,
+	0xba, 0xd9, 0xbe, 0xb9, 0x7d, 0x7d, 0xdf, 0xb8, 0x4f, 0xa0, 0x93, 0xb3, 0xa2, 0x6b, 0xc9, 0x4a,
+	0x6b, 0x69, 0x07, 0xaa, 0xbf, 0x06, 0x8b, 0x35, 0xd6, 0x63, 0x42, 0x10, 0x8f, 0xcb, 0x8f, 0xac,
+	0xa7, 0x00, 0x8d, 0xb3, 0x68, 0x81, 0x4f, 0xc8, 0x34, 0xf4, 0x3e, 0x01, 0x24, 0xc7, 0xba, 0x6c,
+	0x9e, 0x2d, 0xfd, 0xf5, 0x8f, 0x05, 0x9d, 0xd7, 0x02, 0x4a, 0x66, 0xba, 0x31, 0x4c, 0x96, 0x7a,
+	0x30, 0xf1, 0x35, 0xfa, 0x14, 0xba, 0x51, 0x3c, 0x5f, 0xac, 0x43, 0x3c, 0x53, 0x09, 0x28, 0x8b,
+	0x04, 0x14, 0xb8, 0xbc, 0x7a, 0x93, 0x78, 0x71, 0xa9, 0x95, 0x6c, 0xa1, 0x94, 0x65, 0x65, 0xae,
+	0xba, 0x4a, 0xee, 0xaa, 0x73, 0xa1, 0xa1, 0xee, 0x36, 0xea, 0x54, 0x45, 0xc4, 0x0d, 0xcd, 0xf3,
+	0x7e, 0x16, 0x2d, 0x18, 0x26, 0xd4, 0xa9, 0x09, 0x91, 0x26, 0xb3, 0xbd, 0x50, 0xdf, 0x68, 0x5f,
+	0x7d, 0xac, 0x6d, 0xed, 0xfb, 0xf9, 0x5b, 0xa8, 0xab, 0xf0, 0xa3, 0xdb, 0xd0, 0xf3, 0x27, 0x47,
+	0xfe, 0xe4, 0xf5, 0x8b, 0x77, 0x87, 0x93, 0xa3, 0x83, 0x37, 0x2f, 0x67, 0xfd, 0x12, 0xea, 0x43,
+	0x5b, 0x33, 0x5f, 0x9d, 0xbc, 0x9a, 0xf4, 0x2d, 0xb4, 0x03, 0x7d, 0xcd, 0xf9, 0xf1, 0x60, 0x3a,
+	0x7b, 0x77, 0x74, 0xe2, 0xf7, 0xcb, 0x68, 0x17, 0x6e, 0x69, 0xee, 0xf4, 0xf8, 0x78, 0x72, 0x38,
+	0x3d, 0x98, 0x4d, 0xfa, 0xf6, 0xf8, 0xef, 0x0a, 0xa8, 0xc7, 0x23, 0x7a, 0x04, 0x90, 0xe6, 0x02,
+	0xe5, 0xea, 0xdb, 0x35, 0x15, 0xb4, 0x99, 0x2d, 0xaf, 0x84, 0xbe, 0x81, 0x9a, 0x3c, 0x07, 0xda,
+	0x35, 0x8f, 0x95, 0x6c, 0xba, 0xdc, 0x41, 0x91, 0x9d, 0xdd, 0x2a, 0xa7, 0x72, 0xba, 0x35, 0xf7,
+	0x16, 0x70, 0x07, 0x45, 0xb6, 0xd9, 0xfa, 0x05, 0xd4, 0xe4, 0x15, 0x5d, 0xc0, 0x6a, 0x76, 0xe4,
+	0x9f, 0x1d, 0x5e, 0x09, 0x3d, 0x84, 0xaa, 0x48, 0x31, 0xda, 0xd1, 0x2a, 0xd9, 0x79, 0xef, 0xee,
+	0x16, 0xb8, 0x66, 0xdf, 0xb7, 0x50, 0x57, 0xb3, 0x12, 0x0d, 0xd2, 0x9e, 0xc8, 0xce, 0x68, 0x77,
+	0x6f, 0x83, 0x6f, 0x76, 0xff, 0x04, 0xb7, 0x36, 0x46, 0x09, 0x1a, 0xe6, 0x41, 0x6e, 0x0e, 0x43,
+	0xf7, 0xfe, 0x35, 0x1a, 0xc6, 0xf6, 0x97, 0x50, 0x57, 0x93, 0x27, 0x45, 0x96, 0x1f, 0x45, 0x6e,
+	0x2e, 0x38, 0x32, 0xe2, 0x72, 0x66, 0xa4, 0x11, 0xcf, 0x0d, 0x20, 0x77, 0x50, 0x64, 0x6b, 0x6f,
+	0xe3, 0x7f, 0xcb, 0x50, 0x0d, 0xc2, 0x65, 0x14, 0xcb, 0x88, 0x88, 0x6f, 0x45, 0x36, 0x22, 0xd9,
+	0x9f, 0x87, 0xbb, 0xb7, 0xc1, 0xcf, 0xc6, 0x53, 0xfd, 0x0f, 0xd2, 0xdd, 0xf9, 0x2f, 0x84, 0xbb,
+	0xb7, 0xc1, 0x37, 0xbb, 0x9f, 0x41, 0x37, 0xff, 0x15, 0x40, 0x77, 0xf3, 0xd5, 0x59, 0x78, 0x0a,
+	0xbb, 0x1b, 0x2f, 0x68, 0xaf, 0x84, 0x5e, 0x41, 0x27, 0xf7, 0x02, 0x47, 0x77, 0xb4, 0xd2, 0x55,
+	0x0f, 0x76, 0xf7, 0xee, 0x16, 0xa9, 0x01, 0x35, 0x33, 0xcf, 0x29, 0x83, 0xea, 0xff, 0x85, 0x92,
+	0x28, 0xc2, 0xba, 0xb7, 0x55, 0xae, 0xad, 0x9e, 0xd6, 0xc4, 0xf7, 0xee, 0xab, 0xff, 0x06, 0x00,
+	0xe3, 0x46, 0x28, 0x9e, 0xee, 0x0d, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -485,6 +1639,11 @@ type SearchClient interface {
 	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
 	Update(ctx context.Context, in *File, opts ...grpc.CallOption) (*UpdateResponse, error)
+	Trash(ctx context.Context, in *TrashRequest, opts ...grpc.CallOption) (*TrashResponse, error)
+	Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreResponse, error)
+	UpdatePermissions(ctx context.Context, in *UpdatePermissionsRequest, opts ...grpc.CallOption) (*UpdatePermissionsResponse, error)
+	GetFile(ctx context.Context, in *GetFileRequest, opts ...grpc.CallOption) (*File, error)
+	Exists(ctx context.Context, in *ExistsRequest, opts ...grpc.CallOption) (*ExistsResponse, error)
 }
 
 type searchClient struct {
@@ -531,12 +1690,62 @@ func (c *searchClient) Update(ctx context.Context, in *File, opts ...grpc.CallOp
 	return out, nil
 }
 
+func (c *searchClient) Trash(ctx context.Context, in *TrashRequest, opts ...grpc.CallOption) (*TrashResponse, error) {
+	out := new(TrashResponse)
+	err := c.cc.Invoke(ctx, "/search.search/Trash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreResponse, error) {
+	out := new(RestoreResponse)
+	err := c.cc.Invoke(ctx, "/search.search/Restore", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) UpdatePermissions(ctx context.Context, in *UpdatePermissionsRequest, opts ...grpc.CallOption) (*UpdatePermissionsResponse, error) {
+	out := new(UpdatePermissionsResponse)
+	err := c.cc.Invoke(ctx, "/search.search/UpdatePermissions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) GetFile(ctx context.Context, in *GetFileRequest, opts ...grpc.CallOption) (*File, error) {
+	out := new(File)
+	err := c.cc.Invoke(ctx, "/search.search/GetFile", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) Exists(ctx context.Context, in *ExistsRequest, opts ...grpc.CallOption) (*ExistsResponse, error) {
+	out := new(ExistsResponse)
+	err := c.cc.Invoke(ctx, "/search.search/Exists", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SearchServer is the server API for Search service.
 type SearchServer interface {
 	CreateFile(context.Context, *File) (*CreateFileResponse, error)
 	Search(context.Context, *SearchRequest) (*SearchResponse, error)
 	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
 	Update(context.Context, *File) (*UpdateResponse, error)
+	Trash(context.Context, *TrashRequest) (*TrashResponse, error)
+	Restore(context.Context, *RestoreRequest) (*RestoreResponse, error)
+	UpdatePermissions(context.Context, *UpdatePermissionsRequest) (*UpdatePermissionsResponse, error)
+	GetFile(context.Context, *GetFileRequest) (*File, error)
+	Exists(context.Context, *ExistsRequest) (*ExistsResponse, error)
 }
 
 // UnimplementedSearchServer can be embedded to have forward compatible implementations.
@@ -555,6 +1764,21 @@ func (*UnimplementedSearchServer) Delete(ctx context.Context, req *DeleteRequest
 func (*UnimplementedSearchServer) Update(ctx context.Context, req *File) (*UpdateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
 }
+func (*UnimplementedSearchServer) Trash(ctx context.Context, req *TrashRequest) (*TrashResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Trash not implemented")
+}
+func (*UnimplementedSearchServer) Restore(ctx context.Context, req *RestoreRequest) (*RestoreResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
+func (*UnimplementedSearchServer) UpdatePermissions(ctx context.Context, req *UpdatePermissionsRequest) (*UpdatePermissionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePermissions not implemented")
+}
+func (*UnimplementedSearchServer) GetFile(ctx context.Context, req *GetFileRequest) (*File, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFile not implemented")
+}
+func (*UnimplementedSearchServer) Exists(ctx context.Context, req *ExistsRequest) (*ExistsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Exists not implemented")
+}
 
 func RegisterSearchServer(s *grpc.Server, srv SearchServer) {
 	s.RegisterService(&_Search_serviceDesc, srv)
@@ -632,6 +1856,96 @@ func _Search_Update_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Search_Trash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).Trash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/search.search/Trash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).Trash(ctx, req.(*TrashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/search.search/Restore",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).Restore(ctx, req.(*RestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_UpdatePermissions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePermissionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).UpdatePermissions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/search.search/UpdatePermissions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).UpdatePermissions(ctx, req.(*UpdatePermissionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_GetFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).GetFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/search.search/GetFile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).GetFile(ctx, req.(*GetFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_Exists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExistsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).Exists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/search.search/Exists",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).Exists(ctx, req.(*ExistsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Search_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "search.search",
 	HandlerType: (*SearchServer)(nil),
@@ -652,6 +1966,242 @@ var _Search_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Update",
 			Handler:    _Search_Update_Handler,
 		},
+		{
+			MethodName: "Trash",
+			Handler:    _Search_Trash_Handler,
+		},
+		{
+			MethodName: "Restore",
+			Handler:    _Search_Restore_Handler,
+		},
+		{
+			MethodName: "UpdatePermissions",
+			Handler:    _Search_UpdatePermissions_Handler,
+		},
+		{
+			MethodName: "GetFile",
+			Handler:    _Search_GetFile_Handler,
+		},
+		{
+			MethodName: "Exists",
+			Handler:    _Search_Exists_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "search.proto",
+}
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AdminClient interface {
+	Reindex(ctx context.Context, in *ReindexRequest, opts ...grpc.CallOption) (*ReindexResponse, error)
+	Migrate(ctx context.Context, in *MigrateRequest, opts ...grpc.CallOption) (*MigrateResponse, error)
+	CreateSnapshot(ctx context.Context, in *CreateSnapshotRequest, opts ...grpc.CallOption) (*Snapshot, error)
+	ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error)
+	RestoreSnapshot(ctx context.Context, in *RestoreSnapshotRequest, opts ...grpc.CallOption) (*RestoreSnapshotResponse, error)
+}
+
+type adminClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAdminClient(cc *grpc.ClientConn) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) Reindex(ctx context.Context, in *ReindexRequest, opts ...grpc.CallOption) (*ReindexResponse, error) {
+	out := new(ReindexResponse)
+	err := c.cc.Invoke(ctx, "/search.admin/Reindex", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Migrate(ctx context.Context, in *MigrateRequest, opts ...grpc.CallOption) (*MigrateResponse, error) {
+	out := new(MigrateResponse)
+	err := c.cc.Invoke(ctx, "/search.admin/Migrate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) CreateSnapshot(ctx context.Context, in *CreateSnapshotRequest, opts ...grpc.CallOption) (*Snapshot, error) {
+	out := new(Snapshot)
+	err := c.cc.Invoke(ctx, "/search.admin/CreateSnapshot", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error) {
+	out := new(ListSnapshotsResponse)
+	err := c.cc.Invoke(ctx, "/search.admin/ListSnapshots", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) RestoreSnapshot(ctx context.Context, in *RestoreSnapshotRequest, opts ...grpc.CallOption) (*RestoreSnapshotResponse, error) {
+	out := new(RestoreSnapshotResponse)
+	err := c.cc.Invoke(ctx, "/search.admin/RestoreSnapshot", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServer is the server API for Admin service.
+type AdminServer interface {
+	Reindex(context.Context, *ReindexRequest) (*ReindexResponse, error)
+	Migrate(context.Context, *MigrateRequest) (*MigrateResponse, error)
+	CreateSnapshot(context.Context, *CreateSnapshotRequest) (*Snapshot, error)
+	ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error)
+	RestoreSnapshot(context.Context, *RestoreSnapshotRequest) (*RestoreSnapshotResponse, error)
+}
+
+// UnimplementedAdminServer can be embedded to have forward compatible implementations.
+type UnimplementedAdminServer struct {
+}
+
+func (*UnimplementedAdminServer) Reindex(ctx context.Context, req *ReindexRequest) (*ReindexResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reindex not implemented")
+}
+func (*UnimplementedAdminServer) Migrate(ctx context.Context, req *MigrateRequest) (*MigrateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Migrate not implemented")
+}
+func (*UnimplementedAdminServer) CreateSnapshot(ctx context.Context, req *CreateSnapshotRequest) (*Snapshot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSnapshot not implemented")
+}
+func (*UnimplementedAdminServer) ListSnapshots(ctx context.Context, req *ListSnapshotsRequest) (*ListSnapshotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSnapshots not implemented")
+}
+func (*UnimplementedAdminServer) RestoreSnapshot(ctx context.Context, req *RestoreSnapshotRequest) (*RestoreSnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreSnapshot not implemented")
+}
+
+func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
+	s.RegisterService(&_Admin_serviceDesc, srv)
+}
+
+func _Admin_Reindex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReindexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Reindex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/search.admin/Reindex",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Reindex(ctx, req.(*ReindexRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Migrate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MigrateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Migrate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/search.admin/Migrate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Migrate(ctx, req.(*MigrateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_CreateSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).CreateSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/search.admin/CreateSnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).CreateSnapshot(ctx, req.(*CreateSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ListSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSnapshotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/search.admin/ListSnapshots",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListSnapshots(ctx, req.(*ListSnapshotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_RestoreSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).RestoreSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/search.admin/RestoreSnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).RestoreSnapshot(ctx, req.(*RestoreSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Admin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "search.admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Reindex",
+			Handler:    _Admin_Reindex_Handler,
+		},
+		{
+			MethodName: "Migrate",
+			Handler:    _Admin_Migrate_Handler,
+		},
+		{
+			MethodName: "CreateSnapshot",
+			Handler:    _Admin_CreateSnapshot_Handler,
+		},
+		{
+			MethodName: "ListSnapshots",
+			Handler:    _Admin_ListSnapshots_Handler,
+		},
+		{
+			MethodName: "RestoreSnapshot",
+			Handler:    _Admin_RestoreSnapshot_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "search.proto",
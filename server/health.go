@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// healthServiceLiveness is the health check service name reporting whether the process is alive,
+	// which it is until the server shuts down. It doesn't depend on the backend, since restarting
+	// the process can't fix the backend. The server's overall health service, named "", reports
+	// liveness too, since it's the one checked by default by the kubernetes grpc probes and grpc_health_probe.
+	healthServiceLiveness = "liveness"
+
+	// healthServiceReadiness is the health check service name reporting whether the server is ready
+	// to serve requests: the backend is healthy and its indices exist, the index mappings match the expected
+	// ones if a drifted mapping fails the server, and the pending writes don't exceed s.maxPendingWrites.
+	healthServiceReadiness = "readiness"
+
+	// healthServiceSearch and healthServiceAdmin are the health check service names reporting
+	// whether the search service and the admin service can serve requests, which is when the backend is healthy.
+	healthServiceSearch = "search.search"
+	healthServiceAdmin  = "search.admin"
+
+	// healthServiceMapping is the health check service name reporting whether the
+	// index mappings match the expected ones.
+	healthServiceMapping = "search.mapping"
+)
+
+// healthCheck is a check whose result is reported as the serving status of its health services.
+type healthCheck struct {
+	// services are the health services reporting the check's result.
+	services []string
+
+	// check returns true if the check passed within ctx, or false otherwise.
+	check func(ctx context.Context) bool
+
+	// gatesReadiness is whether the server is only ready while the check's services are SERVING.
+	gatesReadiness bool
+
+	// failures is the number of times in a row the check failed.
+	failures int
+
+	// serving is whether the check's services are SERVING.
+	serving bool
+}
+
+// healthChecks returns the checks run by the health check worker: the backend health, which is reported
+// by the search and admin services, and the index mappings, which only gate the readiness if the mapping
+// drift policy is to fail, since the server keeps serving a drifted mapping otherwise.
+func (s *SearchServer) healthChecks() []*healthCheck {
+	return []*healthCheck{
+		{
+			services:       []string{healthServiceSearch, healthServiceAdmin},
+			check:          s.SearchService.HealthCheck,
+			gatesReadiness: true,
+		},
+		{
+			services:       []string{healthServiceMapping},
+			check:          s.SearchService.CheckMapping,
+			gatesReadiness: s.mappingGatesReadiness,
+		},
+	}
+}
+
+// healthCheckWorker is running the health checks once in s.healthCheckInterval seconds, until the
+// server shuts down. The health services are NOT_SERVING until their check first passes.
+func (s *SearchServer) healthCheckWorker() {
+	checks := s.healthChecks()
+	for _, check := range checks {
+		s.setServingStatus(check.services, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	}
+
+	s.setServingStatus([]string{healthServiceReadiness}, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	ticker := time.NewTicker(time.Second * time.Duration(s.healthCheckInterval))
+	defer ticker.Stop()
+
+	for {
+		for _, check := range checks {
+			s.runHealthCheck(check)
+		}
+
+		if s.ready(checks) {
+			s.setServingStatus([]string{healthServiceReadiness}, grpc_health_v1.HealthCheckResponse_SERVING)
+		} else {
+			s.setServingStatus([]string{healthServiceReadiness}, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runHealthCheck runs check within s.healthCheckTimeout and sets the serving status of its services.
+// The services are SERVING once the check passes, and NOT_SERVING once it failed
+// s.healthThreshold times in a row, so a single slow or failed check doesn't stop them from serving.
+func (s *SearchServer) runHealthCheck(check *healthCheck) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.healthCheckTimeout)
+	defer cancel()
+
+	if check.check(ctx) {
+		check.failures = 0
+		check.serving = true
+		s.setServingStatus(check.services, grpc_health_v1.HealthCheckResponse_SERVING)
+		return
+	}
+
+	check.failures++
+	if check.failures >= s.healthThreshold {
+		check.serving = false
+		s.setServingStatus(check.services, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	}
+}
+
+// setServingStatus sets the serving status of the health services to status.
+func (s *SearchServer) setServingStatus(
+	services []string,
+	status grpc_health_v1.HealthCheckResponse_ServingStatus,
+) {
+	for _, service := range services {
+		s.healthServer.SetServingStatus(service, status)
+	}
+}
+
+// ready returns true if the services of the checks gating the readiness are SERVING and the pending writes
+// don't exceed s.maxPendingWrites, or false otherwise.
+func (s *SearchServer) ready(checks []*healthCheck) bool {
+	for _, check := range checks {
+		if check.gatesReadiness && !check.serving {
+			return false
+		}
+	}
+
+	if pending := s.SearchService.PendingWrites(); s.maxPendingWrites > 0 && pending > s.maxPendingWrites {
+		s.logger.Warnf("%d pending writes exceed %d, reporting not ready", pending, s.maxPendingWrites)
+		return false
+	}
+
+	return true
+}
+
+// healthHandler returns an http handler responding with the serving status of the health service,
+// with status 200 if it's SERVING, or 503 otherwise.
+func (s *SearchServer) healthHandler(service string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := s.healthServer.Check(r.Context(), &grpc_health_v1.HealthCheckRequest{Service: service})
+		if err != nil || res.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		_, _ = w.Write([]byte(res.GetStatus().String() + "\n"))
+	}
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/meateam/search-service/proto"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestRunHealthCheck(t *testing.T) {
+	const (
+		serving    = grpc_health_v1.HealthCheckResponse_SERVING
+		notServing = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	)
+
+	tests := []struct {
+		name    string
+		results []bool
+		want    []grpc_health_v1.HealthCheckResponse_ServingStatus
+	}{
+		{
+			name:    "passing",
+			results: []bool{true, true},
+			want:    []grpc_health_v1.HealthCheckResponse_ServingStatus{serving, serving},
+		},
+		{
+			name:    "failing below the threshold",
+			results: []bool{true, false, false},
+			want:    []grpc_health_v1.HealthCheckResponse_ServingStatus{serving, serving, serving},
+		},
+		{
+			name:    "failing at the threshold",
+			results: []bool{true, false, false, false, false},
+			want:    []grpc_health_v1.HealthCheckResponse_ServingStatus{serving, serving, serving, notServing, notServing},
+		},
+		{
+			name:    "passing resets the failures",
+			results: []bool{true, false, false, true, false, false, false},
+			want: []grpc_health_v1.HealthCheckResponse_ServingStatus{
+				serving, serving, serving, serving, serving, serving, notServing,
+			},
+		},
+		{
+			name:    "recovering",
+			results: []bool{false, false, false, true},
+			want:    []grpc_health_v1.HealthCheckResponse_ServingStatus{notServing, notServing, notServing, serving},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(newFakeController(), 3)
+			s.setServingStatus([]string{healthServiceSearch}, notServing)
+
+			step := 0
+			check := &healthCheck{
+				services: []string{healthServiceSearch},
+				check: func(ctx context.Context) bool {
+					return tt.results[step]
+				},
+			}
+
+			for ; step < len(tt.results); step++ {
+				s.runHealthCheck(check)
+
+				res, err := s.healthServer.Check(context.Background(),
+					&grpc_health_v1.HealthCheckRequest{Service: healthServiceSearch})
+				if err != nil {
+					t.Fatalf("Check() error = %v", err)
+				}
+
+				if res.GetStatus() != tt.want[step] {
+					t.Errorf("status after check %d = %s, want %s", step+1, res.GetStatus(), tt.want[step])
+				}
+
+				if check.serving != (tt.want[step] == serving) {
+					t.Errorf("serving after check %d = %v, want %v", step+1, check.serving, tt.want[step] == serving)
+				}
+			}
+		})
+	}
+}
+
+func TestReady(t *testing.T) {
+	tests := []struct {
+		name             string
+		backendServing   bool
+		mappingServing   bool
+		mappingGates     bool
+		pendingWrites    int
+		maxPendingWrites int64
+		want             bool
+	}{
+		{name: "serving", backendServing: true, mappingServing: true, mappingGates: true, want: true},
+		{name: "backend not serving", mappingServing: true, mappingGates: true, want: false},
+		{name: "drifted mapping gating readiness", backendServing: true, mappingGates: true, want: false},
+		{name: "drifted mapping not gating readiness", backendServing: true, want: true},
+		{
+			name:             "pending writes at the limit",
+			backendServing:   true,
+			mappingServing:   true,
+			pendingWrites:    2,
+			maxPendingWrites: 2,
+			want:             true,
+		},
+		{
+			name:             "pending writes above the limit",
+			backendServing:   true,
+			mappingServing:   true,
+			pendingWrites:    3,
+			maxPendingWrites: 2,
+			want:             false,
+		},
+		{
+			name:           "pending writes without a limit",
+			backendServing: true,
+			mappingServing: true,
+			pendingWrites:  3,
+			want:           true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			controller := newFakeController()
+			s := newTestServer(controller, 1)
+			s.maxPendingWrites = tt.maxPendingWrites
+			s.mappingGatesReadiness = tt.mappingGates
+
+			checks := s.healthChecks()
+			checks[0].serving = tt.backendServing
+			checks[1].serving = tt.mappingServing
+
+			written := make(chan struct{})
+			for i := 0; i < tt.pendingWrites; i++ {
+				go func() {
+					_, _ = s.SearchService.CreateFile(context.Background(), &pb.File{Id: "file", OwnerID: "owner"})
+					written <- struct{}{}
+				}()
+
+				<-controller.started
+			}
+
+			if got := s.ready(checks); got != tt.want {
+				t.Errorf("ready() = %v, want %v", got, tt.want)
+			}
+
+			close(controller.release)
+			for i := 0; i < tt.pendingWrites; i++ {
+				<-written
+			}
+		})
+	}
+}
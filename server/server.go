@@ -1,17 +1,25 @@
 package server
 
 import (
+	"context"
 	"crypto/tls"
+	"expvar"
+	"fmt"
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	grpc_logrus "github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus"
 	ilogger "github.com/meateam/elasticsearch-logger"
 	pb "github.com/meateam/search-service/proto"
 	"github.com/meateam/search-service/service"
+	"github.com/meateam/search-service/service/bleve"
+	"github.com/meateam/search-service/service/dualwrite"
 	"github.com/meateam/search-service/service/elasticsearch"
+	"github.com/meateam/search-service/service/memory"
+	"github.com/meateam/search-service/service/sqlite"
 	es "github.com/olivere/elastic/v7"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -31,8 +39,46 @@ const (
 	configHealthCheckInterval   = "health_check_interval"
 	configElasticAPMIgnoreURLS  = "elastic_apm_ignore_urls"
 	configElasticsearchSniff    = "elasticsearch_sniff"
+	configTrashRetentionDays    = "trash_retention_days"
+	configPurgeInterval         = "purge_interval"
+	configHTTPPort              = "http_port"
+	configMappingDriftPolicy    = "elasticsearch_mapping_drift"
+	configMigrateOnStartup      = "elasticsearch_migrate_on_startup"
+	configIndexSettingsPath     = "elasticsearch_index_settings_path"
+	configTenants               = "tenants"
+	configOwnerRouting          = "elasticsearch_owner_routing"
+	configRolloverMaxDocs       = "elasticsearch_rollover_max_docs"
+	configRolloverMaxSize       = "elasticsearch_rollover_max_size"
+	configRolloverInterval      = "elasticsearch_rollover_interval"
+	configSnapshotRepository    = "elasticsearch_snapshot_repository"
+	configSnapshotLocation      = "elasticsearch_snapshot_location"
+	configRefresh               = "elasticsearch_refresh"
+	configBackend               = "backend"
+	configBlevePath             = "bleve_path"
+	configSQLitePath            = "sqlite_path"
+	configSecondaryBackend      = "secondary_backend"
+	configSecondaryURL          = "secondary_elasticsearch_url"
+	configSecondaryIndex        = "secondary_elasticsearch_index"
+	configShadowSearch          = "shadow_search"
+	configShadowSearchTimeout   = "shadow_search_timeout"
+	configShadowConcurrency     = "shadow_search_concurrency"
+	configShutdownTimeout       = "shutdown_timeout"
+	configHealthCheckTimeout    = "health_check_timeout"
+	configHealthCheckThreshold  = "health_check_failure_threshold"
+	configMaxPendingWrites      = "readiness_max_pending_writes"
+
+	// backendElasticsearch, backendMemory, backendBleve and backendSQLite are the store backends
+	// selected by configBackend.
+	backendElasticsearch = "elasticsearch"
+	backendMemory        = "memory"
+	backendBleve         = "bleve"
+	backendSQLite        = "sqlite"
 )
 
+// purgedFiles counts the trashed files permanently deleted by the purge worker,
+// exposed at /debug/vars of the http server.
+var purgedFiles = expvar.NewInt("purged_files")
+
 func init() {
 	viper.SetDefault(configPort, "8080")
 	viper.SetDefault(configElasticsearchURL, "http://localhost:9200")
@@ -43,6 +89,33 @@ func init() {
 	viper.SetDefault(configHealthCheckInterval, 3)
 	viper.SetDefault(configElasticAPMIgnoreURLS, "/grpc.health.v1.Health/Check")
 	viper.SetDefault(configElasticsearchSniff, false)
+	viper.SetDefault(configTrashRetentionDays, 0)
+	viper.SetDefault(configPurgeInterval, 3600)
+	viper.SetDefault(configHTTPPort, "")
+	viper.SetDefault(configMappingDriftPolicy, string(elasticsearch.MappingDriftWarn))
+	viper.SetDefault(configMigrateOnStartup, false)
+	viper.SetDefault(configIndexSettingsPath, "")
+	viper.SetDefault(configTenants, "")
+	viper.SetDefault(configOwnerRouting, string(elasticsearch.OwnerRoutingNone))
+	viper.SetDefault(configRolloverMaxDocs, 0)
+	viper.SetDefault(configRolloverMaxSize, "")
+	viper.SetDefault(configRolloverInterval, 300)
+	viper.SetDefault(configSnapshotRepository, "")
+	viper.SetDefault(configSnapshotLocation, "")
+	viper.SetDefault(configRefresh, "none")
+	viper.SetDefault(configBackend, backendElasticsearch)
+	viper.SetDefault(configBlevePath, "data")
+	viper.SetDefault(configSQLitePath, "search.db")
+	viper.SetDefault(configSecondaryBackend, "")
+	viper.SetDefault(configSecondaryURL, "")
+	viper.SetDefault(configSecondaryIndex, "")
+	viper.SetDefault(configShadowSearch, false)
+	viper.SetDefault(configShadowSearchTimeout, 5)
+	viper.SetDefault(configShadowConcurrency, 10)
+	viper.SetDefault(configShutdownTimeout, 30)
+	viper.SetDefault(configHealthCheckTimeout, 3)
+	viper.SetDefault(configHealthCheckThreshold, 3)
+	viper.SetDefault(configMaxPendingWrites, 100)
 	viper.SetEnvPrefix(envPrefix)
 	viper.AutomaticEnv()
 }
@@ -51,10 +124,31 @@ func init() {
 // and its services and configuration.
 type SearchServer struct {
 	*grpc.Server
-	logger              *logrus.Logger
-	port                string
-	healthCheckInterval int
-	SearchService       service.Service
+	logger                *logrus.Logger
+	port                  string
+	httpPort              string
+	healthCheckInterval   int
+	healthCheckTimeout    time.Duration
+	healthThreshold       int
+	maxPendingWrites      int64
+	mappingGatesReadiness bool
+	trashRetention        time.Duration
+	purgeInterval         int
+	rolloverInterval      int
+	shutdownTimeout       time.Duration
+	SearchService         service.Service
+	controller            service.Controller
+	healthServer          *health.Server
+	httpServer            *http.Server
+
+	// ctx is canceled when the server shuts down, stopping the workers tracked by workers.
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+
+	// shutdown makes sure the server shuts down once, and done is closed once it did.
+	shutdown sync.Once
+	done     chan struct{}
 }
 
 // Serve accepts incoming connections on the listener `lis`, creating a new
@@ -64,13 +158,23 @@ type SearchServer struct {
 // this method returns.
 // If `lis` is nil then Serve creates a `net.Listener` with "tcp" network listening
 // on the configured `TCP_PORT`, which defaults to "8080".
-// Serve will return a non-nil error unless Stop or GracefulStop is called.
-func (s SearchServer) Serve(lis net.Listener) {
+// Serve will return a non-nil error unless Shutdown is called, in which case it returns nil
+// once the server shut down. SIGTERM and SIGINT shut the server down.
+// If `HTTP_PORT` is configured, Serve also serves the metrics and probes http server on it.
+func (s *SearchServer) Serve(lis net.Listener) error {
+	go s.shutdownOnSignal()
+
+	if s.httpPort != "" {
+		go s.serveHTTP()
+	}
+
 	listener := lis
 	if lis == nil {
 		l, err := net.Listen("tcp", ":"+s.port)
 		if err != nil {
-			s.logger.Fatalf("failed to listen: %v", err)
+			s.logger.Errorf("failed to listen: %v", err)
+			s.Shutdown()
+			return err
 		}
 
 		listener = l
@@ -78,15 +182,76 @@ func (s SearchServer) Serve(lis net.Listener) {
 
 	s.logger.Infof("listening and serving grpc server on port %s", s.port)
 	if err := s.Server.Serve(listener); err != nil {
-		s.logger.Fatalf(err.Error())
+		s.logger.Errorf("grpc server stopped: %v", err)
+		s.Shutdown()
+		return err
+	}
+
+	<-s.done
+
+	return nil
+}
+
+// serveHTTP serves the process' expvar metrics at /debug/vars, and its liveness and readiness
+// at /livez and /readyz, on s.httpPort.
+func (s *SearchServer) serveHTTP() {
+	s.logger.Infof("listening and serving http server on port %s", s.httpPort)
+	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		s.logger.Errorf("http server stopped: %v", err)
 	}
 }
 
 // NewServer configures and creates a grpc.Server instance with the download service
 // health check service.
 // Configure using environment variables.
+// `BACKEND`: Store backend, one of "elasticsearch", which also supports OpenSearch 1.x and 2.x detected
+// at startup, "memory" to keep the files in memory for tests
+// and local development, "bleve" to keep them in embedded indices on local disk for single-node
+// deployments, or "sqlite" to keep them in a single SQLite database file for edge deployments,
+// which requires building with `-tags sqlite_fts5`. The `ELASTICSEARCH_` variables are ignored
+// by the other backends.
+// `BLEVE_PATH`: Directory the bleve backend keeps its indices in.
+// `SQLITE_PATH`: Database file the sqlite backend keeps the files in.
+// `SECONDARY_BACKEND`: Store backend the files are also written to while migrating to it, one of the `BACKEND`
+// values, disabled if empty. Reads are served from `BACKEND`.
+// `SECONDARY_ELASTICSEARCH_URL`: Elasticsearch URL of an elasticsearch secondary backend,
+// defaults to `ELASTICSEARCH_URL`.
+// `SECONDARY_ELASTICSEARCH_INDEX`: Index of an elasticsearch secondary backend, defaults to `ELASTICSEARCH_INDEX`.
+// An elasticsearch secondary requires either of them, and mustn't be the primary's cluster and index.
+// The other `ELASTICSEARCH_` variables apply to both.
+// `SHADOW_SEARCH`: Whether searches are also run on the secondary backend to compare their results.
+// `SHADOW_SEARCH_TIMEOUT`: Timeout in seconds of a search run on the secondary backend.
+// `SHADOW_SEARCH_CONCURRENCY`: Shadow searches running at once, searches made meanwhile aren't shadowed.
 // `HEALTH_CHECK_INTERVAL`: Interval to update serving state of the health check server.
+// `HEALTH_CHECK_TIMEOUT`: Timeout in seconds of each health check.
+// `HEALTH_CHECK_FAILURE_THRESHOLD`: Times in a row a health check fails before its health services
+// are NOT_SERVING.
+// `READINESS_MAX_PENDING_WRITES`: Pending write requests above which the server isn't ready,
+// disabled if not positive.
 // `PORT`: TCP port on which the grpc server would serve on.
+// `TRASH_RETENTION_DAYS`: Days a trashed file is kept before it's purged, purging is disabled if not positive.
+// Each replica setting it runs its own purges, so set it on a single replica.
+// `PURGE_INTERVAL`: Interval in seconds between purges of trashed files.
+// `HTTP_PORT`: TCP port on which the metrics and probes http server would serve on, disabled if empty.
+// `SHUTDOWN_TIMEOUT`: Seconds the server waits for in-flight requests to complete when shutting down,
+// before cancelling them.
+// `ELASTICSEARCH_MAPPING_DRIFT`: What to do when the index mappings drifted at startup, one of
+// "warn", "fail", which also makes the server not ready while they drifted, or "apply" to add missing fields.
+// `ELASTICSEARCH_MIGRATE_ON_STARTUP`: Whether to apply pending index migrations at startup, one replica at a time.
+// `ELASTICSEARCH_INDEX_SETTINGS_PATH`: JSON or YAML file overriding the index settings and mappings.
+// `TENANTS`: Comma separated tenants each having its own index, selected by the request's `tenant` metadata,
+// which rejects requests of other tenants in every backend.
+// `ELASTICSEARCH_OWNER_ROUTING`: Whether files are routed to shards by their ownerID, one of "none",
+// "write" to route new files while reindexing the existing ones, or "full" to also route owner-scoped searches.
+// `ELASTICSEARCH_ROLLOVER_MAX_DOCS`: Files count at which the write index rolls over, disabled if not positive.
+// `ELASTICSEARCH_ROLLOVER_MAX_SIZE`: Primary shards size at which the write index rolls over, i.e "50gb",
+// disabled if empty.
+// `ELASTICSEARCH_ROLLOVER_INTERVAL`: Interval in seconds between checks of the rollover conditions.
+// `ELASTICSEARCH_SNAPSHOT_REPOSITORY`: Name of the filesystem snapshot repository,
+// snapshots are disabled if empty.
+// `ELASTICSEARCH_SNAPSHOT_LOCATION`: Location of the snapshot repository, within the nodes' `path.repo`.
+// `ELASTICSEARCH_REFRESH`: Refresh policy of write requests that don't specify one, one of "none",
+// "wait_for" to respond once the changes are searchable, or "immediate" to refresh before responding.
 func NewServer(logger *logrus.Logger) *SearchServer {
 	// If no logger is given, create a new default logger for the server.
 	if logger == nil {
@@ -104,43 +269,269 @@ func NewServer(logger *logrus.Logger) *SearchServer {
 		serverOpts...,
 	)
 
-	controller, err := initController()
+	if err := validateIntervals(); err != nil {
+		logger.Fatalf("%v", err)
+	}
+
+	controller, err := initController(logger)
 	if err != nil {
 		logger.Fatalf("%v", err)
 	}
 
-	// Create a search service and register it on the grpc server.
+	// Create a search service and register it and its admin service on the grpc server.
 	searchService := service.NewService(controller, logger)
 	pb.RegisterSearchServer(grpcServer, searchService)
+	pb.RegisterAdminServer(grpcServer, searchService)
 
 	// Create a health server and register it on the grpc server.
+	// The process is alive from now on, see healthServiceLiveness.
 	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(healthServiceLiveness, grpc_health_v1.HealthCheckResponse_SERVING)
 	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
 
+	ctx, cancel := context.WithCancel(context.Background())
 	searchServer := &SearchServer{
-		Server:              grpcServer,
-		logger:              logger,
-		port:                viper.GetString(configPort),
-		healthCheckInterval: viper.GetInt(configHealthCheckInterval),
-		SearchService:       searchService,
+		Server:                grpcServer,
+		logger:                logger,
+		port:                  viper.GetString(configPort),
+		httpPort:              viper.GetString(configHTTPPort),
+		healthCheckInterval:   viper.GetInt(configHealthCheckInterval),
+		healthCheckTimeout:    time.Second * time.Duration(viper.GetInt(configHealthCheckTimeout)),
+		healthThreshold:       viper.GetInt(configHealthCheckThreshold),
+		maxPendingWrites:      viper.GetInt64(configMaxPendingWrites),
+		mappingGatesReadiness: viper.GetString(configMappingDriftPolicy) == string(elasticsearch.MappingDriftFail),
+		trashRetention:        time.Hour * 24 * time.Duration(viper.GetInt(configTrashRetentionDays)),
+		purgeInterval:         viper.GetInt(configPurgeInterval),
+		rolloverInterval:      viper.GetInt(configRolloverInterval),
+		shutdownTimeout:       time.Second * time.Duration(viper.GetInt(configShutdownTimeout)),
+		SearchService:         searchService,
+		controller:            controller,
+		healthServer:          healthServer,
+		httpServer:            &http.Server{Addr: ":" + viper.GetString(configHTTPPort)},
+		ctx:                   ctx,
+		cancel:                cancel,
+		done:                  make(chan struct{}),
 	}
 
+	// Serve the metrics and the liveness and readiness probes on the http server.
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.Handle("/livez", searchServer.healthHandler(healthServiceLiveness))
+	mux.Handle("/readyz", searchServer.healthHandler(healthServiceReadiness))
+	searchServer.httpServer.Handler = mux
+
 	// Health check validation goroutine worker.
-	go searchServer.healthCheckWorker(healthServer)
+	searchServer.startWorker(searchServer.healthCheckWorker)
+
+	// Trashed files retention goroutine worker.
+	if searchServer.trashRetention > 0 {
+		searchServer.startWorker(searchServer.purgeWorker)
+	}
+
+	// Write index rollover goroutine worker.
+	if viper.GetInt64(configRolloverMaxDocs) > 0 || viper.GetString(configRolloverMaxSize) != "" {
+		searchServer.startWorker(searchServer.rolloverWorker)
+	}
 
 	return searchServer
 }
 
-func initController() (service.Controller, error) {
-	controller, err := elasticsearch.NewController(initESConfig())
+func initController(logger *logrus.Logger) (service.Controller, error) {
+	// The secondary is validated before initializing the backends, so a misconfigured secondary
+	// doesn't start writing over the primary's files.
+	if err := validateSecondary(); err != nil {
+		return nil, err
+	}
+
+	primaryURL := viper.GetString(configElasticsearchURL)
+	primaryIndex := viper.GetString(configElasticsearchIndex)
+	primary, err := initBackend(logger, viper.GetString(configBackend), primaryURL, primaryIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	secondaryBackend := viper.GetString(configSecondaryBackend)
+	if secondaryBackend == "" {
+		return primary, nil
+	}
+
+	secondaryURL := viper.GetString(configSecondaryURL)
+	if secondaryURL == "" {
+		secondaryURL = primaryURL
+	}
+
+	secondaryIndex := viper.GetString(configSecondaryIndex)
+	if secondaryIndex == "" {
+		secondaryIndex = primaryIndex
+	}
+
+	secondary, err := initBackend(logger, secondaryBackend, secondaryURL, secondaryIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed initializing the secondary backend: %v", err)
+	}
+
+	opts := []dualwrite.Option{dualwrite.WithLogger(logger)}
+	if viper.GetBool(configShadowSearch) {
+		timeout := time.Duration(viper.GetInt(configShadowSearchTimeout)) * time.Second
+		opts = append(opts,
+			dualwrite.WithShadowSearch(timeout),
+			dualwrite.WithShadowConcurrency(viper.GetInt(configShadowConcurrency)),
+		)
+	}
+
+	logger.Infof("writing the files to both the %s and the %s backends",
+		viper.GetString(configBackend), secondaryBackend)
+
+	return dualwrite.NewController(primary, secondary, opts...), nil
+}
+
+// validateIntervals returns an error if any of the configured intervals or timeouts isn't positive,
+// which would make the health checks always fail or the workers loop without pausing.
+func validateIntervals() error {
+	for _, key := range []string{
+		configHealthCheckInterval,
+		configHealthCheckTimeout,
+		configPurgeInterval,
+		configRolloverInterval,
+		configShadowSearchTimeout,
+	} {
+		if viper.GetInt(key) <= 0 {
+			return fmt.Errorf("%s_%s must be positive, got %d", envPrefix, strings.ToUpper(key), viper.GetInt(key))
+		}
+	}
+
+	return nil
+}
+
+// validateSecondary returns an error if the secondary backend would store the files in the same place
+// as the primary backend: an elasticsearch secondary requires its own URL or index, and mustn't resolve to
+// the primary's cluster and index, while the embedded backends keep their files at a single configured path.
+func validateSecondary() error {
+	primaryBackend := viper.GetString(configBackend)
+	primaryURL := viper.GetString(configElasticsearchURL)
+	primaryIndex := viper.GetString(configElasticsearchIndex)
+	secondaryBackend := viper.GetString(configSecondaryBackend)
+	secondaryURL := viper.GetString(configSecondaryURL)
+	secondaryIndex := viper.GetString(configSecondaryIndex)
+
+	switch secondaryBackend {
+	case backendElasticsearch:
+		if secondaryURL == "" && secondaryIndex == "" {
+			return fmt.Errorf("an elasticsearch secondary backend requires %s_%s or %s_%s", envPrefix,
+				strings.ToUpper(configSecondaryURL), envPrefix, strings.ToUpper(configSecondaryIndex))
+		}
+
+		if primaryBackend != backendElasticsearch {
+			return nil
+		}
+
+		sameIndex := secondaryIndex == "" || secondaryIndex == primaryIndex
+		if sameIndex && (secondaryURL == "" || sameCluster(secondaryURL, primaryURL)) {
+			return fmt.Errorf("the secondary backend resolves to the primary's index %s on %s", primaryIndex, primaryURL)
+		}
+	case backendBleve, backendSQLite:
+		if secondaryBackend == primaryBackend {
+			return fmt.Errorf("the secondary %s backend resolves to the primary's files", secondaryBackend)
+		}
+	}
+
+	return nil
+}
+
+// sameCluster returns true if the comma separated elasticsearch urls a and b share any node.
+func sameCluster(a string, b string) bool {
+	nodes := make(map[string]bool)
+	for _, node := range strings.Split(a, ",") {
+		nodes[strings.ToLower(strings.TrimRight(strings.TrimSpace(node), "/"))] = true
+	}
+
+	for _, node := range strings.Split(b, ",") {
+		if nodes[strings.ToLower(strings.TrimRight(strings.TrimSpace(node), "/"))] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func initBackend(
+	logger *logrus.Logger,
+	backend string,
+	elasticURL string,
+	elasticIndex string,
+) (service.Controller, error) {
+	switch backend {
+	case backendElasticsearch:
+		return initESController(logger, elasticURL, elasticIndex)
+	case backendMemory:
+		logger.Warn("using the memory backend, files are lost when the service stops")
+		return memory.NewController(memory.WithTenants(configuredTenants()...)), nil
+	case backendBleve:
+		return bleve.NewController(viper.GetString(configBlevePath), bleve.WithTenants(configuredTenants()...))
+	case backendSQLite:
+		return sqlite.NewController(viper.GetString(configSQLitePath), sqlite.WithTenants(configuredTenants()...))
+	default:
+		return nil, fmt.Errorf("invalid backend: %s", backend)
+	}
+}
+
+// configuredTenants returns the tenants configured by TENANTS, or nil if multi-tenancy is disabled.
+func configuredTenants() []string {
+	tenants := viper.GetString(configTenants)
+	if tenants == "" {
+		return nil
+	}
+
+	return strings.Split(tenants, ",")
+}
+
+func initESController(logger *logrus.Logger, elasticURL string, index string) (service.Controller, error) {
+	elasticOpts := initESConfig(elasticURL)
+	refresh, ok := pb.Refresh_value["REFRESH_"+strings.ToUpper(viper.GetString(configRefresh))]
+	if !ok {
+		return nil, fmt.Errorf("invalid refresh policy: %s", viper.GetString(configRefresh))
+	}
+
+	storeOpts := []elasticsearch.Option{
+		elasticsearch.WithLogger(logger),
+		elasticsearch.WithMappingDriftPolicy(
+			elasticsearch.MappingDriftPolicy(viper.GetString(configMappingDriftPolicy)),
+		),
+		elasticsearch.WithMigrateOnStartup(viper.GetBool(configMigrateOnStartup)),
+		elasticsearch.WithOwnerRouting(elasticsearch.OwnerRouting(viper.GetString(configOwnerRouting))),
+		elasticsearch.WithRollover(viper.GetInt64(configRolloverMaxDocs), viper.GetString(configRolloverMaxSize)),
+		elasticsearch.WithRefresh(pb.Refresh(refresh)),
+	}
+
+	if settingsPath := viper.GetString(configIndexSettingsPath); settingsPath != "" {
+		settings, err := elasticsearch.LoadIndexSettings(settingsPath)
+		if err != nil {
+			return nil, err
+		}
+
+		storeOpts = append(storeOpts, elasticsearch.WithIndexSettings(settings))
+	}
+
+	if repository := viper.GetString(configSnapshotRepository); repository != "" {
+		storeOpts = append(storeOpts, elasticsearch.WithSnapshotRepository(
+			repository,
+			viper.GetString(configSnapshotLocation),
+		))
+	}
+
+	if tenants := configuredTenants(); len(tenants) > 0 {
+		storeOpts = append(storeOpts, elasticsearch.WithTenants(tenants...))
+	}
+
+	controller, err := elasticsearch.NewController(elasticOpts, index, storeOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return controller, nil
 }
 
-func initESConfig() ([]es.ClientOptionFunc, string) {
-	elasticURL := viper.GetString(configElasticsearchURL)
+func initESConfig(elasticURL string) []es.ClientOptionFunc {
 	transCfg := &http.Transport{
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: viper.GetBool(configTLSSkipVerify), // ignore expired SSL certificates
@@ -160,7 +551,7 @@ func initESConfig() ([]es.ClientOptionFunc, string) {
 		elasticOpts = append(elasticOpts, es.SetBasicAuth(elasticUser, elasticPassword))
 	}
 
-	return elasticOpts, viper.GetString(configElasticsearchIndex)
+	return elasticOpts
 }
 
 // serverLoggerInterceptor configures the logger interceptor for the search server.
@@ -192,16 +583,41 @@ func serverLoggerInterceptor(logger *logrus.Logger) []grpc.ServerOption {
 	)
 }
 
-// healthCheckWorker is running an infinite loop that sets the serving status once
-// in s.healthCheckInterval seconds.
-func (s SearchServer) healthCheckWorker(healthServer *health.Server) {
+// purgeWorker is running a loop that permanently deletes the files trashed
+// more than s.trashRetention ago once in s.purgeInterval seconds, until the server shuts down,
+// which cancels a running purge.
+func (s *SearchServer) purgeWorker() {
 	for {
-		if s.SearchService.HealthCheck() {
-			healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+		purged, err := s.SearchService.PurgeTrashed(s.ctx, s.trashRetention)
+		if err != nil {
+			s.logger.Errorf("failed purging trashed files: %v", err)
 		} else {
-			healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+			purgedFiles.Add(purged)
+			s.logger.Infof("purged %d files trashed more than %s ago", purged, s.trashRetention)
+		}
+
+		if !s.sleep(time.Second * time.Duration(s.purgeInterval)) {
+			return
+		}
+	}
+}
+
+// rolloverWorker is running a loop that rolls the write indices that are too large
+// over to new indices once in s.rolloverInterval seconds, until the server shuts down,
+// which cancels a running rollover.
+func (s *SearchServer) rolloverWorker() {
+	for {
+		rolledOver, err := s.SearchService.Rollover(s.ctx)
+		if err != nil {
+			s.logger.Errorf("failed rolling over indices: %v", err)
 		}
 
-		time.Sleep(time.Second * time.Duration(s.healthCheckInterval))
+		for _, index := range rolledOver {
+			s.logger.Infof("rolled over to index %s", index)
+		}
+
+		if !s.sleep(time.Second * time.Duration(s.rolloverInterval)) {
+			return
+		}
 	}
 }
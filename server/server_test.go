@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/meateam/search-service/proto"
+	"github.com/meateam/search-service/service"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+)
+
+func TestValidateIntervals(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   int
+		wantErr bool
+	}{
+		{name: "defaults", key: configHealthCheckInterval, value: 3},
+		{name: "zero health check interval", key: configHealthCheckInterval, value: 0, wantErr: true},
+		{name: "negative health check interval", key: configHealthCheckInterval, value: -1, wantErr: true},
+		{name: "zero health check timeout", key: configHealthCheckTimeout, value: 0, wantErr: true},
+		{name: "zero purge interval", key: configPurgeInterval, value: 0, wantErr: true},
+		{name: "zero rollover interval", key: configRolloverInterval, value: 0, wantErr: true},
+		{name: "zero shadow search timeout", key: configShadowSearchTimeout, value: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			defer viper.Set(tt.key, viper.Get(tt.key))
+			viper.Set(tt.key, tt.value)
+
+			if err := validateIntervals(); (err != nil) != tt.wantErr {
+				t.Errorf("validateIntervals() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeController is a controller whose health is set by the test, whose file creations block until release
+// is closed, and whose purges and rollovers block until they're canceled, signaling started once they start.
+// The cancellations and closing the controller are recorded as events, in order, and reported to hook
+// if it's set. Its other methods aren't implemented.
+type fakeController struct {
+	service.Controller
+	healthy bool
+	drift   []string
+	started chan struct{}
+	release chan struct{}
+	hook    func(event string)
+
+	mu     sync.Mutex
+	events []string
+}
+
+func newFakeController() *fakeController {
+	return &fakeController{healthy: true, started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (c *fakeController) HealthCheck(ctx context.Context) (bool, error) {
+	return c.healthy, nil
+}
+
+func (c *fakeController) MappingDrift(ctx context.Context) ([]string, error) {
+	return c.drift, nil
+}
+
+func (c *fakeController) CreateFile(ctx context.Context, req *pb.File) (*pb.CreateFileResponse, error) {
+	c.started <- struct{}{}
+	select {
+	case <-c.release:
+		return &pb.CreateFileResponse{Id: req.GetId()}, nil
+	case <-ctx.Done():
+		c.record("CreateFile canceled")
+		return nil, ctx.Err()
+	}
+}
+
+func (c *fakeController) PurgeTrashed(ctx context.Context, retention time.Duration) (int64, error) {
+	c.started <- struct{}{}
+	<-ctx.Done()
+	c.record("PurgeTrashed canceled")
+
+	return 0, ctx.Err()
+}
+
+func (c *fakeController) Rollover(ctx context.Context) ([]string, error) {
+	c.started <- struct{}{}
+	<-ctx.Done()
+	c.record("Rollover canceled")
+
+	return nil, ctx.Err()
+}
+
+func (c *fakeController) Close() error {
+	c.record("closed")
+
+	return nil
+}
+
+// record records event, and reports it to c.hook if it's set.
+func (c *fakeController) record(event string) {
+	c.mu.Lock()
+	c.events = append(c.events, event)
+	c.mu.Unlock()
+
+	if c.hook != nil {
+		c.hook(event)
+	}
+}
+
+// recorded returns the events recorded so far.
+func (c *fakeController) recorded() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.events...)
+}
+
+// newTestServer returns a server of controller whose health checks fail threshold times in a row
+// before their services are NOT_SERVING, without serving or starting its workers.
+func newTestServer(controller service.Controller, threshold int) *SearchServer {
+	ctx, cancel := context.WithCancel(context.Background())
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	return &SearchServer{
+		Server:             grpc.NewServer(),
+		logger:             logger,
+		healthCheckTimeout: time.Second,
+		healthThreshold:    threshold,
+		shutdownTimeout:    time.Second,
+		SearchService:      service.NewService(controller, logger),
+		controller:         controller,
+		healthServer:       health.NewServer(),
+		httpServer:         &http.Server{},
+		ctx:                ctx,
+		cancel:             cancel,
+		done:               make(chan struct{}),
+	}
+}
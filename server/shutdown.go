@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// startWorker runs worker in a goroutine tracked by s.workers, which the shutdown waits for.
+func (s *SearchServer) startWorker(worker func()) {
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		worker()
+	}()
+}
+
+// sleep waits for d, returns false if the server started shutting down meanwhile.
+func (s *SearchServer) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-s.ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// shutdownOnSignal shuts the server down once the process receives SIGTERM or SIGINT.
+func (s *SearchServer) shutdownOnSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	defer signal.Stop(signals)
+
+	select {
+	case sig := <-signals:
+		s.logger.Infof("received %s, shutting down", sig)
+		s.Shutdown()
+	case <-s.done:
+	}
+}
+
+// Shutdown gracefully shuts the server down, once:
+// it reports NOT_SERVING on all of the health check services so no new requests are routed to it,
+// stops accepting requests and waits up to s.shutdownTimeout for the in-flight requests to complete
+// before cancelling them, stops the workers and waits for their current run to complete,
+// and finally closes the controller if it holds resources, such as embedded indices, flushing their writes.
+func (s *SearchServer) Shutdown() {
+	s.shutdown.Do(func() {
+		defer close(s.done)
+
+		s.healthServer.Shutdown()
+
+		stopped := make(chan struct{})
+		go func() {
+			s.Server.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-time.After(s.shutdownTimeout):
+			s.logger.Warnf("in-flight requests didn't complete within %s, cancelling them", s.shutdownTimeout)
+			s.Server.Stop()
+		}
+
+		httpCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+		defer cancel()
+		if err := s.httpServer.Shutdown(httpCtx); err != nil {
+			s.logger.Errorf("failed shutting down the http server: %v", err)
+		}
+
+		s.cancel()
+		s.workers.Wait()
+
+		if closer, ok := s.controller.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil {
+				s.logger.Errorf("failed closing the controller: %v", err)
+			}
+		}
+
+		s.logger.Info("server shut down")
+	})
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/meateam/search-service/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestShutdown(t *testing.T) {
+	controller := newFakeController()
+	s := newTestServer(controller, 1)
+	s.shutdownTimeout = 200 * time.Millisecond
+	s.trashRetention = time.Hour
+	s.purgeInterval = 1
+	s.rolloverInterval = 1
+	pb.RegisterSearchServer(s.Server, s.SearchService)
+	grpc_health_v1.RegisterHealthServer(s.Server, s.healthServer)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	httpLis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(lis)
+	}()
+
+	go func() {
+		_ = s.httpServer.Serve(httpLis)
+	}()
+
+	// The workers must be canceled only once the http server shut down.
+	var mu sync.Mutex
+	httpClosed := make(map[string]bool)
+	controller.hook = func(event string) {
+		if event == "PurgeTrashed canceled" || event == "Rollover canceled" {
+			_, err := http.Get("http://" + httpLis.Addr().String())
+			mu.Lock()
+			httpClosed[event] = err != nil
+			mu.Unlock()
+		}
+	}
+
+	s.startWorker(s.purgeWorker)
+	s.startWorker(s.rolloverWorker)
+	<-controller.started
+	<-controller.started
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	created := make(chan error, 1)
+	go func() {
+		_, err := pb.NewSearchClient(conn).CreateFile(context.Background(), &pb.File{Id: "file", OwnerID: "owner"})
+		created <- err
+	}()
+
+	<-controller.started
+
+	start := time.Now()
+	shutdown := make(chan struct{})
+	go func() {
+		s.Shutdown()
+		close(shutdown)
+	}()
+
+	// The server reports NOT_SERVING while the in-flight request is still given time to complete.
+	for {
+		res, err := s.healthServer.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+
+		if res.GetStatus() == grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if events := controller.recorded(); len(events) != 0 {
+		t.Errorf("events before the shutdown timeout = %v, want none", events)
+	}
+
+	<-shutdown
+	if elapsed := time.Since(start); elapsed < s.shutdownTimeout {
+		t.Errorf("Shutdown() took %s, want it to wait %s for the in-flight request", elapsed, s.shutdownTimeout)
+	}
+
+	if err := <-served; err != nil {
+		t.Errorf("Serve() error = %v", err)
+	}
+
+	if code := status.Code(<-created); code != codes.Canceled && code != codes.Unavailable {
+		t.Errorf("CreateFile() code = %s, want %s or %s", code, codes.Canceled, codes.Unavailable)
+	}
+
+	// The request is canceled by the grpc server concurrently with the shutdown, which then cancels the workers
+	// and closes the controller once they are done.
+	var events []string
+	for _, event := range controller.recorded() {
+		if event != "CreateFile canceled" {
+			events = append(events, event)
+		}
+	}
+
+	if len(events) != 3 || events[2] != "closed" {
+		t.Fatalf("events = %v, want the workers canceled and then the controller closed", events)
+	}
+
+	workers := events[:2]
+	sort.Strings(workers)
+	if want := []string{"PurgeTrashed canceled", "Rollover canceled"}; !reflect.DeepEqual(workers, want) {
+		t.Errorf("canceled workers = %v, want %v", workers, want)
+	}
+
+	for _, event := range workers {
+		if !httpClosed[event] {
+			t.Errorf("%s while the http server was serving", event)
+		}
+	}
+}
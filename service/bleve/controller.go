@@ -0,0 +1,36 @@
+package bleve
+
+import (
+	"github.com/meateam/search-service/service"
+)
+
+// backendName is the name of the bleve backend.
+const backendName = "bleve"
+
+// Controller is the search service business logic implementation using the embedded bleve store.
+// The bleve backend has no maintenance operations, so it doesn't support the admin operations.
+type Controller struct {
+	service.FileController
+	service.UnsupportedAdmin
+	store *Store
+}
+
+// NewController returns a new controller with a bleve store keeping its indices under path,
+// configured by opts.
+func NewController(path string, opts ...Option) (*Controller, error) {
+	store, err := NewStore(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Controller{
+		FileController:   service.NewFileController(store),
+		UnsupportedAdmin: service.UnsupportedAdmin{Backend: backendName},
+		store:            store,
+	}, nil
+}
+
+// Close closes the store's indices.
+func (c Controller) Close() error {
+	return c.store.Close()
+}
@@ -0,0 +1,117 @@
+package bleve
+
+import (
+	blevesearch "github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/mapping"
+	pb "github.com/meateam/search-service/proto"
+)
+
+// Names of the document fields.
+const (
+	fieldText             = "text"
+	fieldName             = "name"
+	fieldOwnerID          = "ownerID"
+	fieldTrashed          = "trashed"
+	fieldDeletedAt        = "deletedAt"
+	fieldCreatedAt        = "createdAt"
+	fieldUpdatedAt        = "updatedAt"
+	fieldSize             = "size"
+	fieldTags             = "tags"
+	fieldMetadata         = "metadata"
+	fieldPermissionUsers  = "permissionUsers"
+	fieldPermissionGroups = "permissionGroups"
+)
+
+// document is the searchable representation of a file, the file itself is stored separately.
+type document struct {
+	Text             string   `json:"text"`
+	Name             string   `json:"name"`
+	OwnerID          string   `json:"ownerID"`
+	Trashed          bool     `json:"trashed"`
+	DeletedAt        float64  `json:"deletedAt"`
+	CreatedAt        float64  `json:"createdAt"`
+	UpdatedAt        float64  `json:"updatedAt"`
+	Size             float64  `json:"size"`
+	Tags             []string `json:"tags"`
+	Metadata         []string `json:"metadata"`
+	PermissionUsers  []string `json:"permissionUsers"`
+	PermissionGroups []string `json:"permissionGroups"`
+}
+
+// newDocument returns the document of file.
+func newDocument(file *pb.File) document {
+	doc := document{
+		Text:      file.GetName() + " " + file.GetDescription() + " " + file.GetType(),
+		Name:      file.GetName(),
+		OwnerID:   file.GetOwnerID(),
+		Trashed:   file.GetTrashed(),
+		DeletedAt: float64(file.GetDeletedAt()),
+		CreatedAt: float64(file.GetCreatedAt()),
+		UpdatedAt: float64(file.GetUpdatedAt()),
+		Size:      float64(file.GetSize()),
+		Tags:      file.GetTags(),
+	}
+
+	for key, value := range file.GetMetadata() {
+		doc.Metadata = append(doc.Metadata, metadataTerm(key, value))
+	}
+
+	for _, permission := range file.GetPermissions() {
+		if permission.GetUserID() != "" {
+			doc.PermissionUsers = append(doc.PermissionUsers, permission.GetUserID())
+		}
+
+		if permission.GetGroupID() != "" {
+			doc.PermissionGroups = append(doc.PermissionGroups, permission.GetGroupID())
+		}
+	}
+
+	return doc
+}
+
+// metadataTerm returns the term a metadata value is indexed as.
+func metadataTerm(key string, value string) string {
+	return key + "=" + value
+}
+
+// newIndexMapping returns the mapping of the documents, with the text analyzed as words
+// and the rest of the string fields indexed as exact keywords.
+func newIndexMapping() mapping.IndexMapping {
+	text := blevesearch.NewTextFieldMapping()
+	text.Analyzer = standard.Name
+	text.Store = false
+
+	docMapping := blevesearch.NewDocumentStaticMapping()
+	docMapping.AddFieldMappingsAt(fieldText, text)
+
+	for _, field := range []string{
+		fieldName,
+		fieldOwnerID,
+		fieldTags,
+		fieldMetadata,
+		fieldPermissionUsers,
+		fieldPermissionGroups,
+	} {
+		keywordField := blevesearch.NewTextFieldMapping()
+		keywordField.Analyzer = keyword.Name
+		keywordField.Store = false
+		docMapping.AddFieldMappingsAt(field, keywordField)
+	}
+
+	for _, field := range []string{fieldDeletedAt, fieldCreatedAt, fieldUpdatedAt, fieldSize} {
+		numeric := blevesearch.NewNumericFieldMapping()
+		numeric.Store = false
+		docMapping.AddFieldMappingsAt(field, numeric)
+	}
+
+	trashed := blevesearch.NewBooleanFieldMapping()
+	trashed.Store = false
+	docMapping.AddFieldMappingsAt(fieldTrashed, trashed)
+
+	indexMapping := blevesearch.NewIndexMapping()
+	indexMapping.DefaultMapping = docMapping
+
+	return indexMapping
+}
@@ -0,0 +1,97 @@
+package bleve
+
+import (
+	"strings"
+	"unicode"
+
+	blevesearch "github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/search/query"
+	"github.com/meateam/search-service/service"
+)
+
+// searchQuery returns the bleve query matching the files of q, or nil if it can't match any file.
+// The words of the search term are matched as prefixes of the words of the files' text.
+func searchQuery(q service.Query) query.Query {
+	words := tokenize(q.Term)
+	if len(words) == 0 {
+		return nil
+	}
+
+	prefixes := make([]query.Query, 0, len(words))
+	for _, word := range words {
+		prefixes = append(prefixes, fieldQuery(blevesearch.NewPrefixQuery(word), fieldText))
+	}
+
+	boolQuery := blevesearch.NewBooleanQuery()
+	boolQuery.AddShould(prefixes...)
+	boolQuery.AddMust(filterQueries(q)...)
+
+	if q.Trashed == service.TrashedExclude {
+		boolQuery.AddMustNot(trashedQuery())
+	}
+
+	return boolQuery
+}
+
+// filterQueries returns the queries of the filters of q that must all match.
+func filterQueries(q service.Query) []query.Query {
+	filters := make([]query.Query, 0)
+	if q.Trashed == service.TrashedOnly {
+		filters = append(filters, trashedQuery())
+	}
+
+	// Scope the results to the files the user owns or was granted access to.
+	if q.UserID != "" {
+		access := []query.Query{
+			termQuery(fieldOwnerID, q.UserID),
+			termQuery(fieldPermissionUsers, q.UserID),
+		}
+
+		for _, groupID := range q.GroupIDs {
+			access = append(access, termQuery(fieldPermissionGroups, groupID))
+		}
+
+		filters = append(filters, blevesearch.NewDisjunctionQuery(access...))
+	}
+
+	if q.OwnerID != "" {
+		filters = append(filters, termQuery(fieldOwnerID, q.OwnerID))
+	}
+
+	for _, tag := range q.Tags {
+		filters = append(filters, termQuery(fieldTags, tag))
+	}
+
+	for key, value := range q.Metadata {
+		filters = append(filters, termQuery(fieldMetadata, metadataTerm(key, value)))
+	}
+
+	return filters
+}
+
+// trashedQuery returns a query matching the trashed files.
+func trashedQuery() query.Query {
+	trashed := blevesearch.NewBoolFieldQuery(true)
+	trashed.SetField(fieldTrashed)
+
+	return trashed
+}
+
+// termQuery returns a query matching the exact term in field.
+func termQuery(field string, term string) query.Query {
+	return fieldQuery(blevesearch.NewTermQuery(term), field)
+}
+
+// fieldQuery sets the field of q and returns it.
+func fieldQuery(q query.FieldableQuery, field string) query.Query {
+	q.SetField(field)
+
+	return q
+}
+
+// tokenize splits text into its lowercase words, like the standard analyzer the text is indexed with.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,463 @@
+package bleve
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	blevesearch "github.com/blevesearch/bleve"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	pb "github.com/meateam/search-service/proto"
+	"github.com/meateam/search-service/service"
+)
+
+// indexName is the name of the directory of the files index, with the tenants' indices
+// suffixed by their tenant, i.e `files-<tenant>`.
+const indexName = "files"
+
+// purgeBatchSize is the number of trashed files deleted in each batch when purging.
+const purgeBatchSize = 1000
+
+// tenantPattern matches the valid tenant names, which are used as directory names.
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Store is an embedded implementation of service.Store, keeping the files in bleve indices on local disk.
+// Each of the configured tenants has its own index, opened when first used.
+// Writes are visible to search right away, regardless of their refresh policy.
+type Store struct {
+	path    string
+	tenants []string
+
+	// mu guards indices, and serializes the writes since updates read and rewrite the files.
+	mu      sync.Mutex
+	indices map[string]blevesearch.Index
+}
+
+// Option configures optional behavior of the Store.
+type Option func(*Store)
+
+// WithTenants sets the tenants each having its own index, selected by the request's tenant metadata,
+// which must be one of them. Defaults to no tenants, rejecting requests that have a tenant.
+func WithTenants(tenants ...string) Option {
+	return func(s *Store) {
+		s.tenants = tenants
+	}
+}
+
+// NewStore returns a new Store keeping its indices under the directory at path,
+// which is created if it doesn't exist.
+func NewStore(path string, opts ...Option) (*Store, error) {
+	store := &Store{path: path, indices: make(map[string]blevesearch.Index)}
+	for _, opt := range opts {
+		opt(store)
+	}
+
+	for _, tenant := range store.tenants {
+		if !tenantPattern.MatchString(tenant) {
+			return nil, fmt.Errorf("invalid tenant %q: must match %s", tenant, tenantPattern)
+		}
+	}
+
+	if err := os.MkdirAll(path, 0750); err != nil {
+		return nil, err
+	}
+
+	if len(store.tenants) == 0 {
+		if _, err := store.index(""); err != nil {
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
+
+// Close closes the store's indices.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for tenant, index := range s.indices {
+		if err := index.Close(); err != nil {
+			return err
+		}
+
+		delete(s.indices, tenant)
+	}
+
+	return nil
+}
+
+// HealthCheck checks the health of the store, returns true if its indices are usable, or false otherwise.
+func (s *Store) HealthCheck(ctx context.Context) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, index := range s.indices {
+		if _, err := index.DocCount(); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// Get finds the file with the given id.
+// If successful returns the file and a nil error,
+// otherwise returns nil and non-nil error if any occurred.
+func (s *Store) Get(ctx context.Context, id string) (*pb.File, error) {
+	index, err := s.tenantIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return getFile(index, id)
+}
+
+// Exists finds which of the files with the given ids exist.
+// If successful returns the ids of the existing files and a nil error,
+// otherwise returns nil and non-nil error if any occurred.
+func (s *Store) Exists(ctx context.Context, ids []string) ([]string, error) {
+	index, err := s.tenantIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make([]string, 0, len(ids))
+	for _, id := range ids {
+		source, err := index.GetInternal(sourceKey(id))
+		if err != nil {
+			return nil, err
+		}
+
+		if source != nil {
+			existing = append(existing, id)
+		}
+	}
+
+	return existing, nil
+}
+
+// GetAll finds the files that match query,
+// if successful returns the ids of the files, and a nil error,
+// otherwise returns nil and non-nil error if any occurred.
+func (s *Store) GetAll(ctx context.Context, query service.Query) ([]string, error) {
+	index, err := s.tenantIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	searchQuery := searchQuery(query)
+	if searchQuery == nil {
+		return []string{}, nil
+	}
+
+	// Results are sorted by relevance, with ties broken by id.
+	req := blevesearch.NewSearchRequestOptions(searchQuery, service.SearchSize, 0, false)
+	req.SortBy([]string{"-_score", "_id"})
+
+	res, err := index.SearchInContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		ids = append(ids, hit.ID)
+	}
+
+	return ids, nil
+}
+
+// Create creates a file, generating its id if it has none.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) Create(ctx context.Context, file *pb.File, refresh pb.Refresh) (string, error) {
+	if file.GetId() == "" {
+		id, err := service.NewFileID()
+		if err != nil {
+			return "", err
+		}
+
+		file = proto.Clone(file).(*pb.File)
+		file.Id = id
+	}
+
+	index, err := s.tenantIndex(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := putFile(index, file); err != nil {
+		return "", err
+	}
+
+	return file.GetId(), nil
+}
+
+// Delete file from store by id.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) Delete(ctx context.Context, id string, refresh pb.Refresh) (string, error) {
+	index, err := s.tenantIndex(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := getFile(index, id); err != nil {
+		return "", err
+	}
+
+	batch := index.NewBatch()
+	batch.Delete(id)
+	batch.DeleteInternal(sourceKey(id))
+	if err := index.Batch(batch); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// Update partially updates the file with the fields set in file.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) Update(ctx context.Context, file *pb.File, refresh pb.Refresh) (string, error) {
+	return s.update(ctx, file.GetId(), func(stored *pb.File) {
+		service.MergeFile(stored, file)
+	})
+}
+
+// Trash marks the file as trashed at deletedAt.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) Trash(ctx context.Context, id string, deletedAt int64, refresh pb.Refresh) (string, error) {
+	return s.update(ctx, id, func(stored *pb.File) {
+		stored.Trashed = true
+		stored.DeletedAt = deletedAt
+	})
+}
+
+// Restore clears the trashed state of the file.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) Restore(ctx context.Context, id string, refresh pb.Refresh) (string, error) {
+	return s.update(ctx, id, func(stored *pb.File) {
+		stored.Trashed = false
+		stored.DeletedAt = 0
+	})
+}
+
+// UpdatePermissions replaces the permissions granted on the file.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) UpdatePermissions(
+	ctx context.Context,
+	id string,
+	permissions []*pb.Permission,
+	refresh pb.Refresh,
+) (string, error) {
+	return s.update(ctx, id, func(stored *pb.File) {
+		stored.Permissions = permissions
+	})
+}
+
+// PurgeTrashed permanently deletes all files that were trashed before trashedBefore,
+// of the tenant of the request in ctx, or of all tenants if ctx has none.
+// If successful returns the number of deleted files and a nil error,
+// otherwise returns 0 and non-nil error if any occurred.
+func (s *Store) PurgeTrashed(ctx context.Context, trashedBefore int64) (int64, error) {
+	indices, err := s.tenantIndices(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for _, index := range indices {
+		deleted, err := purgeTrashed(ctx, index, trashedBefore)
+		purged += deleted
+		if err != nil {
+			return purged, err
+		}
+	}
+
+	return purged, nil
+}
+
+// purgeTrashed permanently deletes the files of index that were trashed before trashedBefore.
+func purgeTrashed(ctx context.Context, index blevesearch.Index, trashedBefore int64) (int64, error) {
+	before := float64(trashedBefore)
+	inclusive := true
+	deletedAt := blevesearch.NewNumericRangeInclusiveQuery(nil, &before, nil, &inclusive)
+	deletedAt.SetField(fieldDeletedAt)
+
+	query := blevesearch.NewConjunctionQuery(trashedQuery(), deletedAt)
+
+	var purged int64
+	for {
+		res, err := index.SearchInContext(ctx, blevesearch.NewSearchRequestOptions(query, purgeBatchSize, 0, false))
+		if err != nil {
+			return purged, err
+		}
+
+		if len(res.Hits) == 0 {
+			return purged, nil
+		}
+
+		batch := index.NewBatch()
+		for _, hit := range res.Hits {
+			batch.Delete(hit.ID)
+			batch.DeleteInternal(sourceKey(hit.ID))
+		}
+
+		if err := index.Batch(batch); err != nil {
+			return purged, err
+		}
+
+		purged += int64(len(res.Hits))
+	}
+}
+
+// update applies apply to the stored file with the given id and reindexes it.
+func (s *Store) update(ctx context.Context, id string, apply func(stored *pb.File)) (string, error) {
+	index, err := s.tenantIndex(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := getFile(index, id)
+	if err != nil {
+		return "", err
+	}
+
+	apply(stored)
+	if err := putFile(index, stored); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// tenantIndex returns the index of the tenant of the request in ctx, see service.RequestTenant.
+func (s *Store) tenantIndex(ctx context.Context) (blevesearch.Index, error) {
+	tenant, err := service.RequestTenant(ctx, s.tenants)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.index(tenant)
+}
+
+// tenantIndices returns the index of the tenant of the request in ctx,
+// or the indices of all of the configured tenants if ctx has none.
+func (s *Store) tenantIndices(ctx context.Context) ([]blevesearch.Index, error) {
+	if service.TenantFromContext(ctx) != "" || len(s.tenants) == 0 {
+		index, err := s.tenantIndex(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return []blevesearch.Index{index}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	indices := make([]blevesearch.Index, 0, len(s.tenants))
+	for _, tenant := range s.tenants {
+		index, err := s.index(tenant)
+		if err != nil {
+			return nil, err
+		}
+
+		indices = append(indices, index)
+	}
+
+	return indices, nil
+}
+
+// index returns the index of tenant, opening or creating it if it's not open, s.mu must be held.
+func (s *Store) index(tenant string) (blevesearch.Index, error) {
+	if index, ok := s.indices[tenant]; ok {
+		return index, nil
+	}
+
+	name := indexName
+	if tenant != "" {
+		name = indexName + "-" + tenant
+	}
+
+	path := filepath.Join(s.path, name)
+	index, err := blevesearch.Open(path)
+	if err == blevesearch.ErrorIndexPathDoesNotExist {
+		index, err = blevesearch.New(path, newIndexMapping())
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.indices[tenant] = index
+
+	return index, nil
+}
+
+// getFile returns the file with the given id stored in index.
+func getFile(index blevesearch.Index, id string) (*pb.File, error) {
+	source, err := index.GetInternal(sourceKey(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if source == nil {
+		return nil, fmt.Errorf("file %s: %w", id, service.ErrNotFound)
+	}
+
+	file := &pb.File{}
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err := unmarshaler.Unmarshal(bytes.NewReader(source), file); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// putFile indexes file and stores it in index, replacing the existing file with its id.
+func putFile(index blevesearch.Index, file *pb.File) error {
+	source, err := (&jsonpb.Marshaler{}).MarshalToString(file)
+	if err != nil {
+		return err
+	}
+
+	batch := index.NewBatch()
+	if err := batch.Index(file.GetId(), newDocument(file)); err != nil {
+		return err
+	}
+
+	batch.SetInternal(sourceKey(file.GetId()), []byte(source))
+
+	return index.Batch(batch)
+}
+
+// sourceKey returns the internal key the file with the given id is stored under.
+func sourceKey(id string) []byte {
+	return []byte("file/" + id)
+}
@@ -0,0 +1,42 @@
+package bleve
+
+import (
+	"testing"
+
+	"github.com/meateam/search-service/service"
+	"github.com/meateam/search-service/service/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) service.Store {
+		return newTestStore(t)
+	})
+}
+
+func TestStoreTenants(t *testing.T) {
+	storetest.RunTenants(t, func(t *testing.T, tenants ...string) service.Store {
+		return newTestStore(t, WithTenants(tenants...))
+	})
+}
+
+func TestNewStoreInvalidTenant(t *testing.T) {
+	if _, err := NewStore(t.TempDir(), WithTenants("acme", "../globex")); err == nil {
+		t.Error("NewStore() error = nil, want an invalid tenant error")
+	}
+}
+
+// newTestStore returns a new empty store configured by opts, which is closed when the test completes.
+func newTestStore(t *testing.T, opts ...Option) *Store {
+	store, err := NewStore(t.TempDir(), opts...)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := store.Close(); err != nil {
+			t.Errorf("Close() error = %v", err)
+		}
+	})
+
+	return store
+}
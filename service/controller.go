@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"time"
+
 	pb "github.com/meateam/search-service/proto"
 )
 
@@ -11,5 +13,18 @@ type Controller interface {
 	Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error)
 	Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error)
 	Update(ctx context.Context, req *pb.File) (*pb.UpdateResponse, error)
+	Trash(ctx context.Context, req *pb.TrashRequest) (*pb.TrashResponse, error)
+	Restore(ctx context.Context, req *pb.RestoreRequest) (*pb.RestoreResponse, error)
+	UpdatePermissions(ctx context.Context, req *pb.UpdatePermissionsRequest) (*pb.UpdatePermissionsResponse, error)
+	GetFile(ctx context.Context, req *pb.GetFileRequest) (*pb.File, error)
+	Exists(ctx context.Context, req *pb.ExistsRequest) (*pb.ExistsResponse, error)
+	PurgeTrashed(ctx context.Context, retention time.Duration) (int64, error)
+	Rollover(ctx context.Context) ([]string, error)
+	Reindex(ctx context.Context, req *pb.ReindexRequest) (*pb.ReindexResponse, error)
+	Migrate(ctx context.Context, req *pb.MigrateRequest) (*pb.MigrateResponse, error)
+	CreateSnapshot(ctx context.Context, req *pb.CreateSnapshotRequest) (*pb.Snapshot, error)
+	ListSnapshots(ctx context.Context, req *pb.ListSnapshotsRequest) (*pb.ListSnapshotsResponse, error)
+	RestoreSnapshot(ctx context.Context, req *pb.RestoreSnapshotRequest) (*pb.RestoreSnapshotResponse, error)
 	HealthCheck(ctx context.Context) (bool, error)
+	MappingDrift(ctx context.Context) ([]string, error)
 }
@@ -0,0 +1,62 @@
+package dualwrite
+
+// comparison is the difference between the results of a search on the primary and on the secondary.
+type comparison struct {
+	// overlap is the number of ids found by both divided by the number of ids found by either,
+	// 1 if both found none.
+	overlap float64
+
+	// missing are the ids found only by the primary, and extra are the ids found only by the secondary.
+	missing []string
+	extra   []string
+
+	// reordered is true if the ids found by both are in a different order.
+	reordered bool
+}
+
+// equal returns true if both found the same ids in the same order.
+func (c comparison) equal() bool {
+	return len(c.missing) == 0 && len(c.extra) == 0 && !c.reordered
+}
+
+// compare compares the primary's ids with the secondary's ids.
+func compare(primary []string, secondary []string) comparison {
+	inSecondary := make(map[string]bool, len(secondary))
+	for _, id := range secondary {
+		inSecondary[id] = true
+	}
+
+	inPrimary := make(map[string]bool, len(primary))
+	result := comparison{missing: []string{}, extra: []string{}}
+	common := make([]string, 0, len(primary))
+	for _, id := range primary {
+		inPrimary[id] = true
+		if inSecondary[id] {
+			common = append(common, id)
+		} else {
+			result.missing = append(result.missing, id)
+		}
+	}
+
+	i := 0
+	for _, id := range secondary {
+		if !inPrimary[id] {
+			result.extra = append(result.extra, id)
+			continue
+		}
+
+		if i < len(common) && common[i] != id {
+			result.reordered = true
+		}
+
+		i++
+	}
+
+	union := len(common) + len(result.missing) + len(result.extra)
+	result.overlap = 1
+	if union > 0 {
+		result.overlap = float64(len(common)) / float64(union)
+	}
+
+	return result
+}
@@ -0,0 +1,76 @@
+package dualwrite
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name      string
+		primary   []string
+		secondary []string
+		want      comparison
+	}{
+		{
+			name: "both empty",
+			want: comparison{overlap: 1, missing: []string{}, extra: []string{}},
+		},
+		{
+			name:      "equal",
+			primary:   []string{"a", "b", "c"},
+			secondary: []string{"a", "b", "c"},
+			want:      comparison{overlap: 1, missing: []string{}, extra: []string{}},
+		},
+		{
+			name:      "reordered",
+			primary:   []string{"a", "b", "c"},
+			secondary: []string{"a", "c", "b"},
+			want:      comparison{overlap: 1, missing: []string{}, extra: []string{}, reordered: true},
+		},
+		{
+			name:      "missing",
+			primary:   []string{"a", "b", "c", "d"},
+			secondary: []string{"a", "c"},
+			want:      comparison{overlap: 0.5, missing: []string{"b", "d"}, extra: []string{}},
+		},
+		{
+			name:      "extra in order",
+			primary:   []string{"a", "b", "c"},
+			secondary: []string{"a", "x", "b", "c"},
+			want:      comparison{overlap: 0.75, missing: []string{}, extra: []string{"x"}},
+		},
+		{
+			name:      "missing, extra and reordered",
+			primary:   []string{"a", "b", "c"},
+			secondary: []string{"c", "x", "a"},
+			want: comparison{
+				overlap:   0.5,
+				missing:   []string{"b"},
+				extra:     []string{"x"},
+				reordered: true,
+			},
+		},
+		{
+			name:      "disjoint",
+			primary:   []string{"a"},
+			secondary: []string{"b"},
+			want:      comparison{overlap: 0, missing: []string{"a"}, extra: []string{"b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := compare(tt.primary, tt.secondary)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("compare() = %+v, want %+v", got, tt.want)
+			}
+
+			wantEqual := len(tt.want.missing) == 0 && len(tt.want.extra) == 0 && !tt.want.reordered
+			if got.equal() != wantEqual {
+				t.Errorf("compare().equal() = %v, want %v", got.equal(), wantEqual)
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+// Package dualwrite implements a composite controller for migrating the files between clusters or backends,
+// writing them to both a primary and a secondary controller while serving them from the primary.
+package dualwrite
+
+import (
+	"context"
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/meateam/search-service/proto"
+	"github.com/meateam/search-service/service"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// defaultShadowTimeout is the default timeout of a shadow search.
+	defaultShadowTimeout = 5 * time.Second
+
+	// defaultShadowConcurrency is the default limit of the shadow searches running at once.
+	defaultShadowConcurrency = 10
+)
+
+var (
+	// secondaryWriteErrors counts the writes that succeeded on the primary but failed on the secondary.
+	secondaryWriteErrors = expvar.NewInt("dualwrite_secondary_write_errors")
+
+	// shadowSearches counts the searches shadowed to the secondary and compared.
+	shadowSearches = expvar.NewInt("dualwrite_shadow_searches")
+
+	// shadowSearchesDropped counts the searches that weren't shadowed since the concurrency limit
+	// of the shadow searches was reached.
+	shadowSearchesDropped = expvar.NewInt("dualwrite_shadow_searches_dropped")
+
+	// shadowSearchErrors counts the shadow searches that failed on the secondary.
+	shadowSearchErrors = expvar.NewInt("dualwrite_shadow_search_errors")
+
+	// shadowSearchDivergences counts the shadow searches whose results differ from the primary's.
+	shadowSearchDivergences = expvar.NewInt("dualwrite_shadow_search_divergences")
+
+	// shadowSearchOverlap sums the overlap of the compared shadow searches, which divided by
+	// shadowSearches is their average overlap.
+	shadowSearchOverlap = expvar.NewFloat("dualwrite_shadow_search_overlap_sum")
+)
+
+// Controller is a service.Controller writing the files to both a primary and a secondary controller,
+// and serving the reads and the admin operations from the primary.
+// A write is made on the secondary only once it succeeded on the primary, and its failure on the secondary
+// is logged and counted without failing the request, since the primary is the source of truth until the
+// migration completes. Files that existed before dual writing started must be copied to the secondary
+// separately, i.e by restoring a snapshot, since updating them fails on the secondary.
+type Controller struct {
+	primary   service.Controller
+	secondary service.Controller
+	logger    *logrus.Logger
+
+	shadowSearch      bool
+	shadowTimeout     time.Duration
+	shadowConcurrency int
+
+	// shadowSlots holds a slot for each running shadow search, up to shadowConcurrency.
+	shadowSlots chan struct{}
+
+	// shadows tracks the running shadow searches, which Close waits for.
+	shadows sync.WaitGroup
+}
+
+// Option configures optional behavior of the Controller.
+type Option func(*Controller)
+
+// WithLogger sets the logger the controller reports the secondary's failures and divergences to.
+// Defaults to the standard logrus logger.
+func WithLogger(logger *logrus.Logger) Option {
+	return func(c *Controller) {
+		c.logger = logger
+	}
+}
+
+// WithShadowSearch enables shadowing every search to the secondary in the background, comparing its results
+// with the primary's, with each shadow search limited to timeout. Defaults to no shadow searches.
+func WithShadowSearch(timeout time.Duration) Option {
+	return func(c *Controller) {
+		c.shadowSearch = true
+		c.shadowTimeout = timeout
+		if timeout <= 0 {
+			c.shadowTimeout = defaultShadowTimeout
+		}
+	}
+}
+
+// WithShadowConcurrency limits the shadow searches running at once to concurrency, searches made while
+// the limit is reached aren't shadowed, so a slow secondary doesn't pile up goroutines.
+// Defaults to 10.
+func WithShadowConcurrency(concurrency int) Option {
+	return func(c *Controller) {
+		if concurrency > 0 {
+			c.shadowConcurrency = concurrency
+		}
+	}
+}
+
+// NewController returns a new controller writing to primary and secondary and reading from primary.
+func NewController(primary service.Controller, secondary service.Controller, opts ...Option) *Controller {
+	controller := &Controller{
+		primary:           primary,
+		secondary:         secondary,
+		logger:            logrus.StandardLogger(),
+		shadowConcurrency: defaultShadowConcurrency,
+	}
+
+	for _, opt := range opts {
+		opt(controller)
+	}
+
+	controller.shadowSlots = make(chan struct{}, controller.shadowConcurrency)
+
+	return controller
+}
+
+// HealthCheck runs the primary's healthcheck, the secondary's health is logged but doesn't affect
+// the service's health.
+func (c *Controller) HealthCheck(ctx context.Context) (bool, error) {
+	if healthy, err := c.secondary.HealthCheck(ctx); !healthy || err != nil {
+		c.logger.WithError(err).Warn("secondary backend is unhealthy")
+	}
+
+	return c.primary.HealthCheck(ctx)
+}
+
+// CreateFile creates the file on the primary, and then on the secondary with the id given to it
+// by the primary.
+func (c *Controller) CreateFile(ctx context.Context, req *pb.File) (*pb.CreateFileResponse, error) {
+	// The primary's controller may format the request, so the secondary gets a copy of the original.
+	secondaryReq := proto.Clone(req).(*pb.File)
+
+	res, err := c.primary.CreateFile(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	secondaryReq.Id = res.GetId()
+	_, err = c.secondary.CreateFile(ctx, secondaryReq)
+	c.secondaryWritten("CreateFile", res.GetId(), err)
+
+	return res, nil
+}
+
+// Update updates the file on the primary, and then on the secondary.
+func (c *Controller) Update(ctx context.Context, req *pb.File) (*pb.UpdateResponse, error) {
+	secondaryReq := proto.Clone(req).(*pb.File)
+
+	res, err := c.primary.Update(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.secondary.Update(ctx, secondaryReq)
+	c.secondaryWritten("Update", req.GetId(), err)
+
+	return res, nil
+}
+
+// Delete deletes the file from the primary, and then from the secondary.
+func (c *Controller) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
+	res, err := c.primary.Delete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.secondary.Delete(ctx, req)
+	c.secondaryWritten("Delete", req.GetId(), err)
+
+	return res, nil
+}
+
+// Trash trashes the file on the primary, and then on the secondary.
+func (c *Controller) Trash(ctx context.Context, req *pb.TrashRequest) (*pb.TrashResponse, error) {
+	res, err := c.primary.Trash(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.secondary.Trash(ctx, req)
+	c.secondaryWritten("Trash", req.GetId(), err)
+
+	return res, nil
+}
+
+// Restore restores the trashed file on the primary, and then on the secondary.
+func (c *Controller) Restore(ctx context.Context, req *pb.RestoreRequest) (*pb.RestoreResponse, error) {
+	res, err := c.primary.Restore(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.secondary.Restore(ctx, req)
+	c.secondaryWritten("Restore", req.GetId(), err)
+
+	return res, nil
+}
+
+// UpdatePermissions replaces the permissions granted on the file on the primary, and then on the secondary.
+func (c *Controller) UpdatePermissions(
+	ctx context.Context,
+	req *pb.UpdatePermissionsRequest,
+) (*pb.UpdatePermissionsResponse, error) {
+	res, err := c.primary.UpdatePermissions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.secondary.UpdatePermissions(ctx, req)
+	c.secondaryWritten("UpdatePermissions", req.GetId(), err)
+
+	return res, nil
+}
+
+// PurgeTrashed purges the trashed files from the primary, and then from the secondary,
+// returns the number of files purged from the primary.
+func (c *Controller) PurgeTrashed(ctx context.Context, retention time.Duration) (int64, error) {
+	purged, err := c.primary.PurgeTrashed(ctx, retention)
+	if err != nil {
+		return purged, err
+	}
+
+	_, err = c.secondary.PurgeTrashed(ctx, retention)
+	c.secondaryWritten("PurgeTrashed", "", err)
+
+	return purged, nil
+}
+
+// Search searches the files on the primary, and shadows the search to the secondary if enabled
+// and the concurrency limit of the shadow searches isn't reached.
+func (c *Controller) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	res, err := c.primary.Search(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.shadowSearch {
+		return res, nil
+	}
+
+	select {
+	case c.shadowSlots <- struct{}{}:
+		c.shadows.Add(1)
+		go func() {
+			defer c.shadows.Done()
+			defer func() { <-c.shadowSlots }()
+			c.shadow(ctx, req, res.GetIds())
+		}()
+	default:
+		shadowSearchesDropped.Add(1)
+	}
+
+	return res, nil
+}
+
+// GetFile retrieves the file from the primary.
+func (c *Controller) GetFile(ctx context.Context, req *pb.GetFileRequest) (*pb.File, error) {
+	return c.primary.GetFile(ctx, req)
+}
+
+// Exists retrieves the ids of the given files that exist on the primary.
+func (c *Controller) Exists(ctx context.Context, req *pb.ExistsRequest) (*pb.ExistsResponse, error) {
+	return c.primary.Exists(ctx, req)
+}
+
+// Rollover rolls over the primary's write indices.
+func (c *Controller) Rollover(ctx context.Context) ([]string, error) {
+	return c.primary.Rollover(ctx)
+}
+
+// Reindex reindexes the primary.
+func (c *Controller) Reindex(ctx context.Context, req *pb.ReindexRequest) (*pb.ReindexResponse, error) {
+	return c.primary.Reindex(ctx, req)
+}
+
+// Migrate migrates the primary.
+func (c *Controller) Migrate(ctx context.Context, req *pb.MigrateRequest) (*pb.MigrateResponse, error) {
+	return c.primary.Migrate(ctx, req)
+}
+
+// CreateSnapshot snapshots the primary.
+func (c *Controller) CreateSnapshot(ctx context.Context, req *pb.CreateSnapshotRequest) (*pb.Snapshot, error) {
+	return c.primary.CreateSnapshot(ctx, req)
+}
+
+// ListSnapshots lists the primary's snapshots.
+func (c *Controller) ListSnapshots(
+	ctx context.Context,
+	req *pb.ListSnapshotsRequest,
+) (*pb.ListSnapshotsResponse, error) {
+	return c.primary.ListSnapshots(ctx, req)
+}
+
+// RestoreSnapshot restores a snapshot of the primary.
+func (c *Controller) RestoreSnapshot(
+	ctx context.Context,
+	req *pb.RestoreSnapshotRequest,
+) (*pb.RestoreSnapshotResponse, error) {
+	return c.primary.RestoreSnapshot(ctx, req)
+}
+
+// MappingDrift returns the drift of the primary's index mappings.
+func (c *Controller) MappingDrift(ctx context.Context) ([]string, error) {
+	return c.primary.MappingDrift(ctx)
+}
+
+// Close waits for the running shadow searches to complete, and then closes the primary and the secondary
+// if they hold resources to release.
+func (c *Controller) Close() error {
+	c.shadows.Wait()
+
+	for _, controller := range []service.Controller{c.primary, c.secondary} {
+		if closer, ok := controller.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// secondaryWritten logs and counts the failure of the write op of the file with the given id
+// on the secondary, if it failed.
+func (c *Controller) secondaryWritten(op string, id string, err error) {
+	if err == nil {
+		return
+	}
+
+	secondaryWriteErrors.Add(1)
+	c.logger.WithError(err).WithFields(logrus.Fields{
+		"op": op,
+		"id": id,
+	}).Warn("secondary backend write failed")
+}
+
+// shadow runs req on the secondary and compares its results with the primary's ids.
+// The shadow search outlives the request, so it runs with the request's metadata,
+// which carries its tenant, but without its deadline.
+func (c *Controller) shadow(ctx context.Context, req *pb.SearchRequest, ids []string) {
+	shadowCtx := context.Background()
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		shadowCtx = metadata.NewIncomingContext(shadowCtx, md)
+	}
+
+	shadowCtx, cancel := context.WithTimeout(shadowCtx, c.shadowTimeout)
+	defer cancel()
+
+	res, err := c.secondary.Search(shadowCtx, req)
+	if err != nil {
+		shadowSearchErrors.Add(1)
+		c.logger.WithError(err).Warn("secondary backend shadow search failed")
+		return
+	}
+
+	comparison := compare(ids, res.GetIds())
+	shadowSearches.Add(1)
+	shadowSearchOverlap.Add(comparison.overlap)
+	if comparison.equal() {
+		return
+	}
+
+	shadowSearchDivergences.Add(1)
+	c.logger.WithFields(logrus.Fields{
+		"overlap":   comparison.overlap,
+		"primary":   len(ids),
+		"secondary": len(res.GetIds()),
+		"missing":   comparison.missing,
+		"extra":     comparison.extra,
+		"reordered": comparison.reordered,
+	}).Info("secondary backend shadow search diverged")
+}
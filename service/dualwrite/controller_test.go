@@ -0,0 +1,155 @@
+package dualwrite
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	pb "github.com/meateam/search-service/proto"
+	"github.com/meateam/search-service/service"
+	"github.com/meateam/search-service/service/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// faultyController is a memory controller whose writes fail with err if it's set, and whose searches
+// block until release is closed if it's set, signaling started once they start.
+type faultyController struct {
+	service.Controller
+	err     error
+	started chan struct{}
+	release chan struct{}
+}
+
+func newFaultyController(err error) *faultyController {
+	return &faultyController{Controller: memory.NewController(), err: err}
+}
+
+func (c *faultyController) CreateFile(ctx context.Context, req *pb.File) (*pb.CreateFileResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return c.Controller.CreateFile(ctx, req)
+}
+
+func (c *faultyController) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return c.Controller.Delete(ctx, req)
+}
+
+func (c *faultyController) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	if c.release != nil {
+		c.started <- struct{}{}
+		<-c.release
+	}
+
+	return c.Controller.Search(ctx, req)
+}
+
+func testLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	return logger
+}
+
+func TestControllerPrimaryError(t *testing.T) {
+	ctx := context.Background()
+	primaryErr := errors.New("primary failed")
+	primary := newFaultyController(primaryErr)
+	secondary := newFaultyController(nil)
+	controller := NewController(primary, secondary, WithLogger(testLogger()))
+
+	if _, err := controller.CreateFile(ctx, &pb.File{Id: "file", OwnerID: "owner"}); !errors.Is(err, primaryErr) {
+		t.Fatalf("CreateFile() error = %v, want %v", err, primaryErr)
+	}
+
+	if _, err := secondary.GetFile(ctx, &pb.GetFileRequest{Id: "file"}); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("secondary GetFile() error = %v, want %v", err, service.ErrNotFound)
+	}
+}
+
+func TestControllerSecondaryError(t *testing.T) {
+	ctx := context.Background()
+	primary := newFaultyController(nil)
+	secondary := newFaultyController(errors.New("secondary failed"))
+	controller := NewController(primary, secondary, WithLogger(testLogger()))
+
+	before := secondaryWriteErrors.Value()
+	res, err := controller.CreateFile(ctx, &pb.File{Id: "file", OwnerID: "owner"})
+	if err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+
+	if _, err := primary.GetFile(ctx, &pb.GetFileRequest{Id: res.GetId()}); err != nil {
+		t.Errorf("primary GetFile() error = %v", err)
+	}
+
+	if _, err := controller.Delete(ctx, &pb.DeleteRequest{Id: res.GetId()}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if got := secondaryWriteErrors.Value() - before; got != 2 {
+		t.Errorf("secondary write errors = %d, want %d", got, 2)
+	}
+}
+
+func TestControllerShadowSearch(t *testing.T) {
+	ctx := context.Background()
+	primary := newFaultyController(nil)
+	secondary := newFaultyController(nil)
+	secondary.started = make(chan struct{})
+	secondary.release = make(chan struct{})
+	controller := NewController(primary, secondary,
+		WithLogger(testLogger()), WithShadowSearch(time.Second), WithShadowConcurrency(2))
+
+	if _, err := controller.CreateFile(ctx, &pb.File{Id: "file", Name: "file", OwnerID: "owner"}); err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+
+	searches, dropped := shadowSearches.Value(), shadowSearchesDropped.Value()
+	for i := 0; i < 5; i++ {
+		res, err := controller.Search(ctx, &pb.SearchRequest{Term: "file", OwnerID: "owner"})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+
+		if len(res.GetIds()) != 1 {
+			t.Errorf("Search() = %v, want the file", res.GetIds())
+		}
+
+		// The first searches take the shadow slots, and the rest are dropped while the slots are taken.
+		if i < 2 {
+			<-secondary.started
+		}
+	}
+
+	if got := shadowSearchesDropped.Value() - dropped; got != 3 {
+		t.Errorf("dropped shadow searches = %d, want %d", got, 3)
+	}
+
+	closed := make(chan error)
+	go func() {
+		closed <- controller.Close()
+	}()
+
+	select {
+	case err := <-closed:
+		t.Fatalf("Close() = %v before the shadow searches completed", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(secondary.release)
+	if err := <-closed; err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if got := shadowSearches.Value() - searches; got != 2 {
+		t.Errorf("compared shadow searches = %d, want %d", got, 2)
+	}
+}
@@ -1,5 +1,31 @@
 package elasticsearch
 
+const (
+	// fieldTrashed is the name of the field marking a file as trashed.
+	fieldTrashed = "trashed"
+
+	// fieldDeletedAt is the name of the field holding the time a file was trashed at.
+	fieldDeletedAt = "deletedAt"
+
+	// fieldOwnerID is the name of the keyword field holding the id of the file's owner.
+	fieldOwnerID = "ownerID.keyword"
+
+	// fieldPermissions is the name of the nested field holding the permissions granted on a file.
+	fieldPermissions = "permissions"
+
+	// fieldPermissionsUserID is the name of the field holding the user a permission is granted to.
+	fieldPermissionsUserID = "permissions.userID"
+
+	// fieldPermissionsGroupID is the name of the field holding the group a permission is granted to.
+	fieldPermissionsGroupID = "permissions.groupID"
+
+	// fieldTags is the name of the keyword field holding the tags of a file.
+	fieldTags = "tags"
+
+	// fieldMetadata is the name of the flattened field holding the custom metadata of a file.
+	fieldMetadata = "metadata"
+)
+
 // IndexSettings is the index settings and mappings.
 const IndexSettings string = `
 {
@@ -96,6 +122,9 @@ const IndexSettings string = `
 		"createdAt": {
 		  "type": "long"
 		},
+		"deletedAt": {
+		  "type": "long"
+		},
 		"id": {
 		  "type": "text",
 		  "fields": {
@@ -105,6 +134,9 @@ const IndexSettings string = `
 			}
 		  }
 		},
+		"metadata": {
+		  "type": "flattened"
+		},
 		"name": {
 		  "type": "text",
 		  "fields": {
@@ -128,9 +160,29 @@ const IndexSettings string = `
 			}
 		  }
 		},
+		"permissions": {
+		  "type": "nested",
+		  "properties": {
+			"groupID": {
+			  "type": "keyword"
+			},
+			"role": {
+			  "type": "keyword"
+			},
+			"userID": {
+			  "type": "keyword"
+			}
+		  }
+		},
 		"size": {
 		  "type": "long"
 		},
+		"tags": {
+		  "type": "keyword"
+		},
+		"trashed": {
+		  "type": "boolean"
+		},
 		"type": {
 		  "type": "text",
 		  "fields": {
@@ -2,97 +2,143 @@ package elasticsearch
 
 import (
 	"context"
-	"fmt"
-	"strings"
 
 	pb "github.com/meateam/search-service/proto"
+	"github.com/meateam/search-service/service"
 	es "github.com/olivere/elastic/v7"
 )
 
 // Controller is the search service business logic implementation using elasticsearch store.
+// The files operations are implemented by the embedded service.FileController using the store
+// as a service.Store, while the admin operations are specific to elasticsearch.
 type Controller struct {
+	service.FileController
 	store *Store
 }
 
 // NewController returns a new controller.
-func NewController(cfg []es.ClientOptionFunc, index string) (*Controller, error) {
-	store, err := newStore(cfg, index)
+func NewController(cfg []es.ClientOptionFunc, index string, opts ...Option) (*Controller, error) {
+	store, err := newStore(cfg, index, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Controller{store: store}, nil
+	return &Controller{FileController: service.NewFileController(store), store: store}, nil
 }
 
-// HealthCheck runs store's healthcheck and returns true if healthy, otherwise returns false
-// and any error if occurred.
-func (c Controller) HealthCheck(ctx context.Context) (bool, error) {
-	return c.store.HealthCheck(ctx)
-}
-
-// CreateFile creates a file in store and returns its unique ID.
-func (c Controller) CreateFile(ctx context.Context, req *pb.File) (*pb.CreateFileResponse, error) {
-	file := formatFile(req)
-	id, err := c.store.Create(ctx, file)
+// MappingDrift returns the differences of the live index mappings and settings from
+// the expected ones, and any error if occurred.
+func (c Controller) MappingDrift(ctx context.Context) ([]string, error) {
+	diffs, err := c.store.MappingDrift(ctx)
 	if err != nil {
-		return nil, err
+		return nil, storeError(err)
 	}
 
-	return &pb.CreateFileResponse{Id: id}, nil
+	drift := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		drift = append(drift, diff.String())
+	}
+
+	return drift, nil
 }
 
-// Search retrieves a list of the file ids that match the search term, and any error if occurred.
-func (c Controller) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
-	query := es.NewMultiMatchQuery(req.GetTerm())
-	ids, err := c.store.GetAll(ctx, query)
+// Rollover rolls the write indices that meet the rollover conditions over to new indices,
+// returns the indices rolled over to and any error if occurred.
+func (c Controller) Rollover(ctx context.Context) ([]string, error) {
+	results, err := c.store.Rollover(ctx)
 	if err != nil {
-		return nil, err
+		return nil, storeError(err)
 	}
 
-	return &pb.SearchResponse{Ids: ids}, nil
+	rolledOver := make([]string, 0, len(results))
+	for _, result := range results {
+		if result.RolledOver {
+			rolledOver = append(rolledOver, result.NewIndex)
+		}
+	}
+
+	return rolledOver, nil
 }
 
-// Delete retrieves a file id and id the match file by fild id from store, and any error if occurred.
-func (c Controller) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
-	id := req.GetId()
-	if id == "" {
-		return nil, fmt.Errorf("file id is required")
+// Reindex copies the files into a new version of the index with the current settings
+// and mappings and swaps the index aliases to it, and any error if occurred.
+func (c Controller) Reindex(ctx context.Context, req *pb.ReindexRequest) (*pb.ReindexResponse, error) {
+	res, err := c.store.Reindex(ctx)
+	if err != nil {
+		return nil, storeError(err)
 	}
 
-	res, err := c.store.Delete(ctx, id)
+	return &pb.ReindexResponse{
+		Source:      res.Source,
+		Destination: res.Destination,
+		Total:       res.Total,
+	}, nil
+}
+
+// Migrate applies the index migrations newer than the index schema version,
+// and any error if occurred.
+func (c Controller) Migrate(ctx context.Context, req *pb.MigrateRequest) (*pb.MigrateResponse, error) {
+	from, to, err := c.store.Migrate(ctx)
 	if err != nil {
-		return nil, err
+		return nil, storeError(err)
 	}
 
-	return &pb.DeleteResponse{Id: res}, nil
+	return &pb.MigrateResponse{FromVersion: int64(from), ToVersion: int64(to)}, nil
 }
 
-// Update retrieves a file and update the match file id, and any error if occurred.
-func (c Controller) Update(ctx context.Context, req *pb.File) (*pb.UpdateResponse, error) {
-	id := req.GetId()
-	if id == "" {
-		return nil, fmt.Errorf("file id is required")
+// CreateSnapshot snapshots the index to the snapshot repository, and any error if occurred.
+func (c Controller) CreateSnapshot(ctx context.Context, req *pb.CreateSnapshotRequest) (*pb.Snapshot, error) {
+	snapshot, err := c.store.CreateSnapshot(ctx, req.GetName())
+	if err != nil {
+		return nil, storeError(err)
 	}
 
-	file := formatFile(req)
+	return formatSnapshot(snapshot), nil
+}
 
-	res, err := c.store.Update(ctx, file)
+// ListSnapshots lists the snapshots in the snapshot repository, and any error if occurred.
+func (c Controller) ListSnapshots(
+	ctx context.Context,
+	req *pb.ListSnapshotsRequest,
+) (*pb.ListSnapshotsResponse, error) {
+	snapshots, err := c.store.ListSnapshots(ctx)
 	if err != nil {
-		return nil, err
+		return nil, storeError(err)
 	}
 
-	return &pb.UpdateResponse{Id: res}, nil
+	res := &pb.ListSnapshotsResponse{Snapshots: make([]*pb.Snapshot, 0, len(snapshots))}
+	for _, snapshot := range snapshots {
+		res.Snapshots = append(res.Snapshots, formatSnapshot(snapshot))
+	}
+
+	return res, nil
 }
 
-// formatFile formats a given file so there won't be elastic indexing errors.
-func formatFile(file *pb.File) *pb.File {
-	fileName := formatFileName(file.GetName())
-	file.Name = fileName
-	return file
+// RestoreSnapshot restores the index from a snapshot into new indices behind an alias,
+// and any error if occurred.
+func (c Controller) RestoreSnapshot(
+	ctx context.Context,
+	req *pb.RestoreSnapshotRequest,
+) (*pb.RestoreSnapshotResponse, error) {
+	if req.GetName() == "" {
+		return nil, service.InvalidField("name", "name is required")
+	}
+
+	res, err := c.store.RestoreSnapshot(ctx, req.GetName(), req.GetAlias())
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	return &pb.RestoreSnapshotResponse{Name: req.GetName(), Alias: res.Alias, Indices: res.Indices}, nil
 }
 
-// formatFileName formats a given fileName to prevent elasticsearch startoffset errors.
-func formatFileName(name string) string {
-	fileName := strings.ReplaceAll(name, "_", " ")
-	return fileName
+// formatSnapshot returns the response representation of snapshot.
+func formatSnapshot(snapshot *es.Snapshot) *pb.Snapshot {
+	return &pb.Snapshot{
+		Name:      snapshot.Snapshot,
+		Indices:   snapshot.Indices,
+		State:     snapshot.State,
+		StartTime: snapshot.StartTimeInMillis,
+		EndTime:   snapshot.EndTimeInMillis,
+	}
 }
@@ -0,0 +1,138 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	es "github.com/olivere/elastic/v7"
+)
+
+// Distribution is the search engine the store is connected to.
+type Distribution string
+
+const (
+	// DistributionElasticsearch is elasticsearch 7.x.
+	DistributionElasticsearch Distribution = "elasticsearch"
+
+	// DistributionOpenSearch is OpenSearch 1.x or 2.x, which is compatible with the elasticsearch 7.10 APIs
+	// the store uses, except for the ones of the elasticsearch default distribution such as the flattened type.
+	DistributionOpenSearch Distribution = "opensearch"
+)
+
+// ClusterInfo is the distribution and version of the cluster the store is connected to.
+type ClusterInfo struct {
+	Distribution Distribution
+	Version      string
+	major        int
+	minor        int
+}
+
+// String returns the distribution and version of the cluster, i.e `opensearch 2.11.0`.
+func (c ClusterInfo) String() string {
+	return fmt.Sprintf("%s %s", c.Distribution, c.Version)
+}
+
+// supportsFlattened returns true if the cluster supports the flattened field type,
+// which was added to the default distribution of elasticsearch in 7.3 and doesn't exist in OpenSearch.
+func (c ClusterInfo) supportsFlattened() bool {
+	return c.Distribution == DistributionElasticsearch && (c.major > 7 || c.major == 7 && c.minor >= 3)
+}
+
+// detectCluster returns the distribution and version the cluster reports on its root endpoint.
+// Returns an error if the cluster isn't a supported version of either distribution.
+func detectCluster(ctx context.Context, client *es.Client) (ClusterInfo, error) {
+	res, err := client.PerformRequest(ctx, es.PerformRequestOptions{Method: "GET", Path: "/"})
+	if err != nil {
+		return ClusterInfo{}, err
+	}
+
+	var body struct {
+		Version struct {
+			Number       string `json:"number"`
+			Distribution string `json:"distribution"`
+		} `json:"version"`
+	}
+
+	if err := json.Unmarshal(res.Body, &body); err != nil {
+		return ClusterInfo{}, fmt.Errorf("failed decoding the cluster version: %v", err)
+	}
+
+	// Elasticsearch doesn't report its distribution, while OpenSearch does.
+	info := ClusterInfo{Distribution: DistributionElasticsearch, Version: body.Version.Number}
+	if body.Version.Distribution == string(DistributionOpenSearch) {
+		info.Distribution = DistributionOpenSearch
+	}
+
+	info.major, info.minor, err = parseVersion(info.Version)
+	if err != nil {
+		return ClusterInfo{}, err
+	}
+
+	switch {
+	case info.Distribution == DistributionElasticsearch && info.major == 7:
+	case info.Distribution == DistributionOpenSearch && (info.major == 1 || info.major == 2):
+	// OpenSearch 1.x reports itself as elasticsearch 7.10.2 when `compatibility.override_main_response_version`
+	// is set, for clients that check the elasticsearch version.
+	case info.Distribution == DistributionOpenSearch && info.major == 7:
+	default:
+		return ClusterInfo{}, fmt.Errorf("unsupported cluster %s: expected elasticsearch 7.x or opensearch 1.x or 2.x",
+			info)
+	}
+
+	return info, nil
+}
+
+// parseVersion returns the major and minor numbers of version, i.e `7.10.2`.
+func parseVersion(version string) (int, int, error) {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("invalid cluster version %q", version)
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid cluster version %q", version)
+	}
+
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid cluster version %q", version)
+	}
+
+	return major, minor, nil
+}
+
+// compatProperties returns properties adapted to the cluster: fields of the flattened type are mapped
+// as objects when the cluster doesn't support it, with a dynamic template mapping their sub-fields
+// as keywords, so they're matched by term queries on `<field>.<key>` the same way.
+// Returns the adapted properties and the dynamic templates they require, if any.
+func (s Store) compatProperties(properties map[string]interface{}) (map[string]interface{}, []interface{}) {
+	if s.cluster.supportsFlattened() {
+		return properties, nil
+	}
+
+	adapted := make(map[string]interface{}, len(properties))
+	templates := make([]interface{}, 0)
+	for field, mapping := range properties {
+		adapted[field] = mapping
+		if lookup(mapping, "type") != "flattened" {
+			continue
+		}
+
+		// An object mapping with sub-fields isn't returned with its type, so it's mapped by its properties
+		// rather than by type for the mapping drift check to find it unchanged.
+		adapted[field] = map[string]interface{}{"dynamic": true, "properties": map[string]interface{}{}}
+		templates = append(templates, map[string]interface{}{
+			field + "_keywords": map[string]interface{}{
+				"path_match":         field + ".*",
+				"match_mapping_type": "string",
+				"mapping":            map[string]interface{}{"type": "keyword"},
+			},
+		})
+	}
+
+	return adapted, templates
+}
@@ -0,0 +1,186 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	es "github.com/olivere/elastic/v7"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version   string
+		wantMajor int
+		wantMinor int
+		wantErr   bool
+	}{
+		{version: "7.10.2", wantMajor: 7, wantMinor: 10},
+		{version: "2.11.1", wantMajor: 2, wantMinor: 11},
+		{version: "7.17", wantMajor: 7, wantMinor: 17},
+		{version: "8.0.0-SNAPSHOT", wantMajor: 8, wantMinor: 0},
+		{version: "", wantErr: true},
+		{version: "7", wantErr: true},
+		{version: "x.1.0", wantErr: true},
+		{version: "7.x.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		major, minor, err := parseVersion(tt.version)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseVersion(%q) error = %v, wantErr %v", tt.version, err, tt.wantErr)
+			continue
+		}
+
+		if major != tt.wantMajor || minor != tt.wantMinor {
+			t.Errorf("parseVersion(%q) = %d, %d, want %d, %d", tt.version, major, minor, tt.wantMajor, tt.wantMinor)
+		}
+	}
+}
+
+func TestDetectCluster(t *testing.T) {
+	tests := []struct {
+		name    string
+		root    string
+		want    ClusterInfo
+		wantErr bool
+	}{
+		{
+			name: "elasticsearch 7",
+			root: `{"version": {"number": "7.10.2", "build_flavor": "default"}}`,
+			want: ClusterInfo{Distribution: DistributionElasticsearch, Version: "7.10.2", major: 7, minor: 10},
+		},
+		{
+			name: "opensearch 1",
+			root: `{"version": {"distribution": "opensearch", "number": "1.3.13"}}`,
+			want: ClusterInfo{Distribution: DistributionOpenSearch, Version: "1.3.13", major: 1, minor: 3},
+		},
+		{
+			name: "opensearch 2",
+			root: `{"version": {"distribution": "opensearch", "number": "2.11.1"}}`,
+			want: ClusterInfo{Distribution: DistributionOpenSearch, Version: "2.11.1", major: 2, minor: 11},
+		},
+		{
+			name: "opensearch overriding its version",
+			root: `{"version": {"distribution": "opensearch", "number": "7.10.2"}}`,
+			want: ClusterInfo{Distribution: DistributionOpenSearch, Version: "7.10.2", major: 7, minor: 10},
+		},
+		{
+			name:    "elasticsearch 6",
+			root:    `{"version": {"number": "6.8.23"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "elasticsearch 8",
+			root:    `{"version": {"number": "8.11.0"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "opensearch 3",
+			root:    `{"version": {"distribution": "opensearch", "number": "3.0.0"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid version",
+			root:    `{"version": {"number": "latest"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid response",
+			root:    `not json`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.root))
+			}))
+			defer server.Close()
+
+			client, err := es.NewClient(es.SetURL(server.URL), es.SetSniff(false), es.SetHealthcheck(false))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			got, err := detectCluster(context.Background(), client)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("detectCluster() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("detectCluster() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompatProperties(t *testing.T) {
+	properties := map[string]interface{}{
+		"name":     map[string]interface{}{"type": "text"},
+		"metadata": map[string]interface{}{"type": "flattened"},
+	}
+
+	tests := []struct {
+		name           string
+		cluster        ClusterInfo
+		wantProperties map[string]interface{}
+		wantTemplates  []interface{}
+	}{
+		{
+			name:           "elasticsearch supporting flattened",
+			cluster:        ClusterInfo{Distribution: DistributionElasticsearch, Version: "7.10.2", major: 7, minor: 10},
+			wantProperties: properties,
+		},
+		{
+			name:    "elasticsearch before flattened",
+			cluster: ClusterInfo{Distribution: DistributionElasticsearch, Version: "7.2.1", major: 7, minor: 2},
+			wantProperties: map[string]interface{}{
+				"name":     map[string]interface{}{"type": "text"},
+				"metadata": map[string]interface{}{"dynamic": true, "properties": map[string]interface{}{}},
+			},
+			wantTemplates: []interface{}{metadataKeywordsTemplate()},
+		},
+		{
+			name:    "opensearch",
+			cluster: ClusterInfo{Distribution: DistributionOpenSearch, Version: "2.11.1", major: 2, minor: 11},
+			wantProperties: map[string]interface{}{
+				"name":     map[string]interface{}{"type": "text"},
+				"metadata": map[string]interface{}{"dynamic": true, "properties": map[string]interface{}{}},
+			},
+			wantTemplates: []interface{}{metadataKeywordsTemplate()},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			s := Store{cluster: tt.cluster}
+			gotProperties, gotTemplates := s.compatProperties(properties)
+			if !reflect.DeepEqual(gotProperties, tt.wantProperties) {
+				t.Errorf("compatProperties() properties = %v, want %v", gotProperties, tt.wantProperties)
+			}
+
+			if len(gotTemplates) != len(tt.wantTemplates) ||
+				len(tt.wantTemplates) > 0 && !reflect.DeepEqual(gotTemplates, tt.wantTemplates) {
+				t.Errorf("compatProperties() templates = %v, want %v", gotTemplates, tt.wantTemplates)
+			}
+		})
+	}
+}
+
+// metadataKeywordsTemplate returns the dynamic template mapping the sub-fields of metadata as keywords.
+func metadataKeywordsTemplate() interface{} {
+	return map[string]interface{}{
+		"metadata_keywords": map[string]interface{}{
+			"path_match":         "metadata.*",
+			"match_mapping_type": "string",
+			"mapping":            map[string]interface{}{"type": "keyword"},
+		},
+	}
+}
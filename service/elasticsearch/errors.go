@@ -0,0 +1,43 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/meateam/search-service/service"
+	es "github.com/olivere/elastic/v7"
+)
+
+// fileError returns err, wrapping service.ErrNotFound if it's elasticsearch's not found error
+// of the file with the given id, or the service error matching it otherwise, see storeError.
+func fileError(id string, err error) error {
+	if es.IsNotFound(err) {
+		return fmt.Errorf("file %s: %w", id, service.ErrNotFound)
+	}
+
+	return storeError(err)
+}
+
+// storeError returns err wrapping the service error matching elasticsearch's error, so it's reported
+// to clients with the matching status code: missing resources, i.e snapshots, wrap service.ErrNotFound,
+// version conflicts wrap service.ErrConflict, timeouts wrap context.DeadlineExceeded, and unreachable
+// or overloaded clusters wrap service.ErrUnavailable. Other errors are returned as is.
+func storeError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case es.IsNotFound(err):
+		return fmt.Errorf("%w: %v", service.ErrNotFound, err)
+	case es.IsConflict(err):
+		return fmt.Errorf("%w: %v", service.ErrConflict, err)
+	case es.IsTimeout(err), es.IsStatusCode(err, http.StatusGatewayTimeout):
+		return fmt.Errorf("%w: %v", context.DeadlineExceeded, err)
+	case es.IsConnErr(err),
+		es.IsStatusCode(err, http.StatusServiceUnavailable),
+		es.IsStatusCode(err, http.StatusTooManyRequests):
+		return fmt.Errorf("%w: %v", service.ErrUnavailable, err)
+	default:
+		return err
+	}
+}
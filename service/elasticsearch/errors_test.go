@@ -0,0 +1,118 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/meateam/search-service/service"
+	es "github.com/olivere/elastic/v7"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStoreError(t *testing.T) {
+	raw := &es.Error{Status: http.StatusBadRequest, Details: &es.ErrorDetails{Type: "parsing_exception"}}
+
+	tests := []struct {
+		name     string
+		err      error
+		want     error
+		wantCode codes.Code
+	}{
+		{
+			name: "missing snapshot",
+			err: &es.Error{
+				Status:  http.StatusNotFound,
+				Details: &es.ErrorDetails{Type: "snapshot_missing_exception"},
+			},
+			want:     service.ErrNotFound,
+			wantCode: codes.NotFound,
+		},
+		{
+			name: "missing index",
+			err: &es.Error{
+				Status:  http.StatusNotFound,
+				Details: &es.ErrorDetails{Type: "index_not_found_exception"},
+			},
+			want:     service.ErrNotFound,
+			wantCode: codes.NotFound,
+		},
+		{
+			name: "version conflict",
+			err: &es.Error{
+				Status:  http.StatusConflict,
+				Details: &es.ErrorDetails{Type: "version_conflict_engine_exception"},
+			},
+			want:     service.ErrConflict,
+			wantCode: codes.Aborted,
+		},
+		{
+			name:     "gateway timeout",
+			err:      &es.Error{Status: http.StatusGatewayTimeout},
+			want:     context.DeadlineExceeded,
+			wantCode: codes.DeadlineExceeded,
+		},
+		{
+			name:     "unavailable",
+			err:      &es.Error{Status: http.StatusServiceUnavailable},
+			want:     service.ErrUnavailable,
+			wantCode: codes.Unavailable,
+		},
+		{
+			name:     "too many requests",
+			err:      &es.Error{Status: http.StatusTooManyRequests},
+			want:     service.ErrUnavailable,
+			wantCode: codes.Unavailable,
+		},
+		{
+			name:     "no available connection",
+			err:      es.ErrNoClient,
+			want:     service.ErrUnavailable,
+			wantCode: codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := storeError(tt.err)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("storeError() = %v, want it to wrap %v", err, tt.want)
+			}
+
+			if code := status.Code(service.Status(err)); code != tt.wantCode {
+				t.Errorf("Status(storeError()) code = %s, want %s", code, tt.wantCode)
+			}
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		if err := storeError(nil); err != nil {
+			t.Errorf("storeError() = %v, want nil", err)
+		}
+	})
+
+	t.Run("other", func(t *testing.T) {
+		err := storeError(raw)
+		if err != raw {
+			t.Errorf("storeError() = %v, want %v", err, raw)
+		}
+
+		if code := status.Code(service.Status(err)); code != codes.Unknown {
+			t.Errorf("Status(storeError()) code = %s, want %s", code, codes.Unknown)
+		}
+	})
+}
+
+func TestFileError(t *testing.T) {
+	err := fileError("file", &es.Error{Status: http.StatusNotFound})
+	if !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("fileError() = %v, want it to wrap %v", err, service.ErrNotFound)
+	}
+
+	if err.Error() != "file file: "+service.ErrNotFound.Error() {
+		t.Errorf("fileError() = %q, want the file id", err)
+	}
+}
@@ -0,0 +1,478 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meateam/search-service/service"
+	es "github.com/olivere/elastic/v7"
+)
+
+// indexVersionPattern matches the version suffix of a versioned index, i.e `files_v3`,
+// or of a rollover generation of a versioned index, i.e `files_v3-000002`.
+var indexVersionPattern = regexp.MustCompile(`_v(\d+)(-\d+)?$`)
+
+// ReindexResult is the outcome of reindexing the files into a new version of the index.
+type ReindexResult struct {
+	// Source is the comma separated indices the files were copied from.
+	Source string
+
+	// Destination is the new index the aliases now point at.
+	Destination string
+
+	// Total is the number of files copied.
+	Total int64
+}
+
+// writeAlias returns the name of the alias files are written through for index.
+func writeAlias(index string) string {
+	return index + "_write"
+}
+
+// versionedIndex returns the name of the physical index of the given version behind alias.
+func versionedIndex(alias string, version int) string {
+	return fmt.Sprintf("%s_v%d", alias, version)
+}
+
+// indexVersion returns the version of a versioned index, or 0 for an index created before
+// the index was versioned.
+func indexVersion(index string) int {
+	match := indexVersionPattern.FindStringSubmatch(index)
+	if match == nil {
+		return 0
+	}
+
+	version, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0
+	}
+
+	return version
+}
+
+// ensureIndex makes sure the index and write aliases exist.
+// If neither exist, the first version of the index is created behind them.
+// An index created before aliases were introduced keeps being used under its own name,
+// with the write alias added to it, until it's reindexed.
+func (s Store) ensureIndex(ctx context.Context) error {
+	exists, err := s.client.IndexExists(s.index).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return s.createIndex(ctx, s.newIndex(1), latestSchemaVersion(), s.index, s.writeIndex)
+	}
+
+	writeExists, err := s.client.IndexExists(s.writeIndex).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if writeExists {
+		return nil
+	}
+
+	index, err := s.resolveIndex(ctx, s.index)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.client.Alias().Add(index, s.writeIndex).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !res.Acknowledged {
+		return fmt.Errorf("failed adding alias %s to index %s", s.writeIndex, index)
+	}
+
+	return nil
+}
+
+// newIndex returns the name of the physical index of the given version behind the index alias.
+// When rollover is enabled, it's the first generation of the version, i.e `files_v1-000001`.
+func (s Store) newIndex(version int) string {
+	if s.rolloverEnabled() {
+		return fmt.Sprintf("%s-%06d", versionedIndex(s.index, version), 1)
+	}
+
+	return versionedIndex(s.index, version)
+}
+
+// createIndex creates index with the store's index settings, schemaVersion recorded as its schema version,
+// and the given aliases, with the write alias being the index's write index.
+// When tenants or rollover are enabled, the index gets its settings and mappings from an index template.
+func (s Store) createIndex(ctx context.Context, index string, schemaVersion int, aliases ...string) error {
+	body := map[string]interface{}{"mappings": map[string]interface{}{}}
+	if len(s.tenants) == 0 && !s.rolloverEnabled() {
+		indexBody, err := s.indexBody()
+		if err != nil {
+			return err
+		}
+
+		body = indexBody
+	}
+
+	if mappings, ok := body["mappings"].(map[string]interface{}); ok {
+		mappings["_meta"] = map[string]interface{}{fieldSchemaVersion: schemaVersion}
+	}
+
+	if len(aliases) > 0 {
+		aliasesBody := make(map[string]interface{}, len(aliases))
+		for _, alias := range aliases {
+			aliasBody := map[string]interface{}{}
+			if alias == s.writeIndex {
+				aliasBody["is_write_index"] = true
+			}
+
+			aliasesBody[alias] = aliasBody
+		}
+
+		body["aliases"] = aliasesBody
+	}
+
+	createIndex, err := s.client.CreateIndex(index).BodyJson(body).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !createIndex.Acknowledged {
+		return fmt.Errorf("failed creating index: %s", index)
+	}
+
+	return nil
+}
+
+// resolveIndex returns the single physical index behind name, which is either an alias or an index.
+func (s Store) resolveIndex(ctx context.Context, name string) (string, error) {
+	indices, err := s.resolveIndices(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	if len(indices) != 1 {
+		return "", fmt.Errorf("expected a single index behind %s, found %d", name, len(indices))
+	}
+
+	return indices[0], nil
+}
+
+// resolveWriteIndex returns the physical index the files are written to through the write alias.
+func (s Store) resolveWriteIndex(ctx context.Context) (string, error) {
+	res, err := s.client.Aliases().Index(s.writeIndex).Do(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return writeIndexOf(res, s.writeIndex)
+}
+
+// writeIndexOf returns the write index of alias in res. A rollover keeps the former generations behind the
+// write alias with `is_write_index` set to false, so the write index is the one it's set to true on, or the
+// only index behind alias if it isn't set, i.e on an index created before aliases were introduced.
+func writeIndexOf(res *es.AliasesResult, alias string) (string, error) {
+	indices := res.IndicesByAlias(alias)
+	for _, index := range indices {
+		for _, aliasResult := range res.Indices[index].Aliases {
+			if aliasResult.AliasName == alias && aliasResult.IsWriteIndex {
+				return index, nil
+			}
+		}
+	}
+
+	if len(indices) == 1 {
+		return indices[0], nil
+	}
+
+	return "", fmt.Errorf("expected a write index behind %s, found %d indices without one", alias, len(indices))
+}
+
+// resolveIndices returns the physical indices behind name, which is either an alias or an index.
+func (s Store) resolveIndices(ctx context.Context, name string) ([]string, error) {
+	res, err := s.client.Aliases().Index(name).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]string, 0, len(res.Indices))
+	for index := range res.Indices {
+		indices = append(indices, index)
+	}
+
+	return indices, nil
+}
+
+// Reindex copies the files of all of the indices behind the index alias into the next version of the index,
+// created with the current index settings, and then atomically swaps the aliases to point at it,
+// without pausing the writes. When owner routing is enabled, the copied files are routed by their ownerID.
+// While the files are copied, every write is mirrored to the new index by all of the service's replicas,
+// and both the copied and the mirrored files keep their version in the source index as an external version,
+// so a file's latest version is kept regardless of the order they arrive in, and deleted files aren't recreated.
+// Files purged meanwhile may be copied, and are purged again by the next purge.
+// The source indices are kept for rollback and should be deleted manually. An index created before aliases
+// were introduced is named as the index alias, so it's cloned into a closed `<index>_v0` backup before
+// being deleted for the alias to take its name.
+func (s Store) Reindex(ctx context.Context) (*ReindexResult, error) {
+	s, err := s.tenantStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.reindex(ctx)
+}
+
+// reindex reindexes the store's index, see Reindex.
+func (s Store) reindex(ctx context.Context) (*ReindexResult, error) {
+	writeSource, err := s.resolveWriteIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sources, err := s.resolveIndices(ctx, s.index)
+	if err != nil {
+		return nil, err
+	}
+
+	legacy := writeSource == s.index
+	if !legacy && len(sources) == 1 && sources[0] == s.index {
+		// A reindex of the legacy index failed after moving the write alias, so it's completed.
+		if err := s.retireLegacyIndex(ctx, writeSource); err != nil {
+			return nil, err
+		}
+
+		return &ReindexResult{Source: s.index, Destination: writeSource}, nil
+	}
+
+	running, err := s.reindexDestination(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if running != "" {
+		return nil, fmt.Errorf("%w: index %s is already being reindexed into %s",
+			service.ErrConflict, s.index, running)
+	}
+
+	// The source schema version is kept, since migrations backfilling files may not have
+	// been applied to the copied files yet.
+	schemaVersion, err := s.schemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creating the destination with the reindex alias starts mirroring the writes to it.
+	// It fails if another reindex created the same version of the index meanwhile.
+	destination := s.newIndex(indexVersion(writeSource) + 1)
+	if err := s.createIndex(ctx, destination, schemaVersion, reindexAlias(s.index)); err != nil {
+		return nil, err
+	}
+
+	swapped := false
+	defer func() {
+		if !swapped {
+			s.abortReindex(destination)
+		}
+	}()
+
+	gcDeletes := map[string]interface{}{"gc_deletes": reindexGCDeletes}
+	if err := s.putIndexSettings(ctx, destination, gcDeletes); err != nil {
+		return nil, err
+	}
+
+	// Wait for all of the replicas to see the reindex alias, so their writes are mirrored before the copy starts.
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(2 * reindexCheckInterval):
+	}
+
+	copied, err := s.client.Reindex().
+		Source(es.NewReindexSource().Index(sources...)).
+		Destination(es.NewReindexDestination().Index(destination).VersionType("external")).
+		Script(s.reindexScript()).
+		ProceedOnVersionConflict().
+		WaitForCompletion(true).
+		Refresh("true").
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if legacy {
+		err = s.swapLegacyWriteAlias(ctx, destination)
+	} else {
+		err = s.swapAliases(ctx, destination)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	swapped = true
+	if err := s.putIndexSettings(ctx, destination, map[string]interface{}{"gc_deletes": nil}); err != nil {
+		s.logger.Warnf("failed resetting gc_deletes of index %s: %v", destination, err)
+	}
+
+	if legacy {
+		if err := s.retireLegacyIndex(ctx, destination); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ReindexResult{
+		Source:      strings.Join(sources, ","),
+		Destination: destination,
+		Total:       copied.Created + copied.Updated,
+	}, nil
+}
+
+// abortReindex deletes the destination of a reindex that failed before the aliases were swapped to it.
+// The reindex alias is removed first, and the destination is deleted once the replicas stopped mirroring
+// the writes to it, since a write mirrored to a deleted index would create it again.
+func (s Store) abortReindex(destination string) {
+	ctx := context.Background()
+	_, err := s.client.Alias().Action(es.NewAliasRemoveAction(reindexAlias(s.index)).Index(destination)).Do(ctx)
+	if err != nil {
+		s.logger.Errorf("failed removing alias %s of the failed reindex: %v", reindexAlias(s.index), err)
+		return
+	}
+
+	time.Sleep(2 * reindexCheckInterval)
+	if _, err := s.client.DeleteIndex(destination).Do(ctx); err != nil {
+		s.logger.Errorf("failed deleting index %s of the failed reindex: %v", destination, err)
+	}
+}
+
+// swapAliases atomically moves the index alias and the write alias from the indices currently behind them,
+// which may include rollover generations created during the reindex, to destination,
+// and removes the reindex alias from it.
+func (s Store) swapAliases(ctx context.Context, destination string) error {
+	writeSources, err := s.resolveIndices(ctx, s.writeIndex)
+	if err != nil {
+		return err
+	}
+
+	sources, err := s.resolveIndices(ctx, s.index)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.client.Alias().Action(
+		es.NewAliasRemoveAction(s.writeIndex).Index(writeSources...),
+		es.NewAliasRemoveAction(s.index).Index(sources...),
+		es.NewAliasRemoveAction(reindexAlias(s.index)).Index(destination),
+		es.NewAliasAddAction(s.index).Index(destination),
+		es.NewAliasAddAction(s.writeIndex).Index(destination).IsWriteIndex(true),
+	).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !res.Acknowledged {
+		return fmt.Errorf("failed swapping aliases from %s to %s", strings.Join(sources, ","), destination)
+	}
+
+	return nil
+}
+
+// swapLegacyWriteAlias atomically moves the write alias from an index created before aliases were introduced,
+// which is named as the index alias, to destination, and removes the reindex alias from it.
+// The index alias is only moved to destination by retireLegacyIndex, so reads from the legacy index
+// miss the files written between the two.
+func (s Store) swapLegacyWriteAlias(ctx context.Context, destination string) error {
+	res, err := s.client.Alias().Action(
+		es.NewAliasRemoveAction(s.writeIndex).Index(s.index),
+		es.NewAliasRemoveAction(reindexAlias(s.index)).Index(destination),
+		es.NewAliasAddAction(s.writeIndex).Index(destination).IsWriteIndex(true),
+	).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !res.Acknowledged {
+		return fmt.Errorf("failed swapping alias %s from %s to %s", s.writeIndex, s.index, destination)
+	}
+
+	return nil
+}
+
+// retireLegacyIndex clones the legacy index, which no longer has the write alias, into a closed
+// `<index>_v0` backup, and then deletes it for the index alias to take its name and point at destination.
+func (s Store) retireLegacyIndex(ctx context.Context, destination string) error {
+	// Writes resolved to the legacy index before the write alias was swapped are mirrored to destination
+	// until the replicas see that the reindex alias was removed.
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(reindexCheckInterval):
+	}
+
+	backup := versionedIndex(s.index, 0)
+	exists, err := s.client.IndexExists(backup).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		if err := s.cloneIndex(ctx, s.index, backup); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.client.CloseIndex(backup).Do(ctx); err != nil {
+		return err
+	}
+
+	res, err := s.client.Alias().Action(
+		es.NewAliasRemoveIndexAction(s.index),
+		es.NewAliasAddAction(s.index).Index(destination),
+	).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !res.Acknowledged {
+		return fmt.Errorf("failed swapping alias %s to %s", s.index, destination)
+	}
+
+	s.logger.Infof("index %s was kept as the closed index %s", s.index, backup)
+
+	return nil
+}
+
+// cloneIndex clones index into target, blocking the writes to index, which is cloned as is.
+func (s Store) cloneIndex(ctx context.Context, index string, target string) error {
+	if err := s.putIndexSettings(ctx, index, map[string]interface{}{"blocks.write": true}); err != nil {
+		return err
+	}
+
+	_, err := s.client.PerformRequest(ctx, es.PerformRequestOptions{
+		Method: http.MethodPut,
+		Path:   fmt.Sprintf("/%s/_clone/%s", url.PathEscape(index), url.PathEscape(target)),
+	})
+
+	return err
+}
+
+// putIndexSettings updates the given dynamic settings of index, a nil value resets a setting to its default.
+func (s Store) putIndexSettings(ctx context.Context, index string, settings map[string]interface{}) error {
+	res, err := s.client.IndexPutSettings(index).
+		BodyJson(map[string]interface{}{"index": settings}).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !res.Acknowledged {
+		return fmt.Errorf("failed updating settings of index %s", index)
+	}
+
+	return nil
+}
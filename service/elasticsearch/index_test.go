@@ -0,0 +1,100 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	es "github.com/olivere/elastic/v7"
+)
+
+func TestResolveWriteIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		aliases string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "single index",
+			aliases: `{"files_v1": {"aliases": {"files_write": {"is_write_index": true}}}}`,
+			want:    "files_v1",
+		},
+		{
+			name:    "legacy alias without is_write_index",
+			aliases: `{"files_v1": {"aliases": {"files_write": {}}}}`,
+			want:    "files_v1",
+		},
+		{
+			name: "rolled over twice",
+			aliases: `{
+				"files_v1": {"aliases": {"files_write": {"is_write_index": false}}},
+				"files_v1-000002": {"aliases": {"files_write": {"is_write_index": false}}},
+				"files_v1-000003": {"aliases": {"files_write": {"is_write_index": true}}}
+			}`,
+			want: "files_v1-000003",
+		},
+		{
+			name: "no write index",
+			aliases: `{
+				"files_v1": {"aliases": {"files_write": {}}},
+				"files_v2": {"aliases": {"files_write": {}}}
+			}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing alias",
+			aliases: `{}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.aliases))
+			}))
+			defer server.Close()
+
+			client, err := es.NewClient(es.SetURL(server.URL), es.SetSniff(false), es.SetHealthcheck(false))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			s := Store{client: client, index: "files", writeIndex: writeAlias("files")}
+			got, err := s.resolveWriteIndex(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveWriteIndex() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("resolveWriteIndex() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexVersion(t *testing.T) {
+	tests := []struct {
+		index string
+		want  int
+	}{
+		{index: "files", want: 0},
+		{index: "files_v1", want: 1},
+		{index: "files_v12", want: 12},
+		{index: "files_tenant_v3", want: 3},
+		{index: "files_v", want: 0},
+		{index: "files_vx", want: 0},
+		{index: "files_v2_restored_nightly", want: 0},
+		{index: versionedIndex("files", 7), want: 7},
+	}
+
+	for _, tt := range tests {
+		if got := indexVersion(tt.index); got != tt.want {
+			t.Errorf("indexVersion(%q) = %d, want %d", tt.index, got, tt.want)
+		}
+	}
+}
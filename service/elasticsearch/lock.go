@@ -0,0 +1,223 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	es "github.com/olivere/elastic/v7"
+)
+
+const (
+	// migrationLockTTL is how long a migration lock is held without a heartbeat before it's considered
+	// abandoned by a replica that stopped while migrating, and may be taken over.
+	migrationLockTTL = 2 * time.Minute
+
+	// migrationLockPollInterval is the interval between attempts to take a migration lock held by another replica.
+	migrationLockPollInterval = 5 * time.Second
+)
+
+// migrationLockIndex returns the name of the index holding the migration locks of the indices of baseIndex,
+// which doesn't match the index templates of the tenants or of the rollover generations.
+func migrationLockIndex(baseIndex string) string {
+	return baseIndex + "_migration_lock"
+}
+
+// migrationLock is the document held by the replica migrating an index.
+type migrationLock struct {
+	Owner       string `json:"owner"`
+	HeartbeatAt int64  `json:"heartbeatAt"`
+}
+
+// heldMigrationLock is the migration lock held by this replica, with the sequence number and primary term
+// of its latest write, which it's only updated and released under so that a lock taken over by another
+// replica is left to it.
+type heldMigrationLock struct {
+	Owner       string `json:"-"`
+	SeqNo       int64  `json:"_seq_no"`
+	PrimaryTerm int64  `json:"_primary_term"`
+}
+
+// params returns the request parameters making a write to the lock conditional on it still being held.
+func (l *heldMigrationLock) params() url.Values {
+	return url.Values{
+		"if_seq_no":       []string{strconv.FormatInt(l.SeqNo, 10)},
+		"if_primary_term": []string{strconv.FormatInt(l.PrimaryTerm, 10)},
+	}
+}
+
+// lockMigrations takes the migration lock of the store's index, so that only one of the service's replicas
+// migrates it, waiting for the replica holding it to release it. The lock is kept alive by a heartbeat
+// until the returned func releases it. The returned context is canceled if the lock is taken over by
+// another replica meanwhile, so the migration is stopped rather than run concurrently with the other's.
+func (s Store) lockMigrations(ctx context.Context) (context.Context, func(), error) {
+	owner, err := os.Hostname()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var lock *heldMigrationLock
+	for {
+		lock, err = s.acquireMigrationLock(ctx, owner)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed taking the migration lock of index %s: %v", s.index, err)
+		}
+
+		if lock != nil {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(migrationLockPollInterval):
+		}
+	}
+
+	migrateCtx, cancel := context.WithCancel(ctx)
+	heartbeatCtx, stop := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		if !s.migrationLockHeartbeat(heartbeatCtx, lock) {
+			cancel()
+		}
+	}()
+
+	return migrateCtx, func() {
+		stop()
+		<-stopped
+		cancel()
+		s.releaseMigrationLock(lock)
+	}, nil
+}
+
+// acquireMigrationLock creates the migration lock of the store's index held by owner,
+// returns nil if another replica holds it. An abandoned lock is deleted to be taken over.
+func (s Store) acquireMigrationLock(ctx context.Context, owner string) (*heldMigrationLock, error) {
+	created, err := s.client.Index().
+		Index(migrationLockIndex(s.baseIndex)).
+		Id(s.index).
+		OpType("create").
+		BodyJson(migrationLock{Owner: owner, HeartbeatAt: time.Now().UnixNano() / int64(time.Millisecond)}).
+		Refresh("true").
+		Do(ctx)
+	if err == nil {
+		return &heldMigrationLock{Owner: owner, SeqNo: created.SeqNo, PrimaryTerm: created.PrimaryTerm}, nil
+	}
+
+	if !es.IsConflict(err) {
+		return nil, err
+	}
+
+	// The lock's sequence number isn't returned by the client's get, so it's read as a raw request
+	// to delete an abandoned lock only if no other replica took it over meanwhile.
+	res, err := s.client.PerformRequest(ctx, es.PerformRequestOptions{
+		Method: http.MethodGet,
+		Path:   s.migrationLockPath(),
+	})
+	if es.IsNotFound(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var held struct {
+		heldMigrationLock
+		Source migrationLock `json:"_source"`
+	}
+	if err := json.Unmarshal(res.Body, &held); err != nil {
+		return nil, err
+	}
+
+	heartbeatAt := time.Unix(0, held.Source.HeartbeatAt*int64(time.Millisecond))
+	if time.Since(heartbeatAt) < migrationLockTTL {
+		s.logger.Infof("waiting for %s to migrate index %s", held.Source.Owner, s.index)
+		return nil, nil
+	}
+
+	s.logger.Warnf("taking over the migration lock of index %s abandoned by %s", s.index, held.Source.Owner)
+	params := held.params()
+	params.Set("refresh", "true")
+	_, err = s.client.PerformRequest(ctx, es.PerformRequestOptions{
+		Method: http.MethodDelete,
+		Path:   s.migrationLockPath(),
+		Params: params,
+	})
+	if es.IsNotFound(err) || es.IsConflict(err) {
+		return nil, nil
+	}
+
+	return nil, err
+}
+
+// migrationLockHeartbeat updates the heartbeat of the migration lock held as lock until ctx is done,
+// recording the sequence number and primary term of each update in lock.
+// Returns false if the lock was taken over by another replica, true once ctx is done.
+func (s Store) migrationLockHeartbeat(ctx context.Context, lock *heldMigrationLock) bool {
+	ticker := time.NewTicker(migrationLockTTL / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true
+		case <-ticker.C:
+		}
+
+		res, err := s.client.PerformRequest(ctx, es.PerformRequestOptions{
+			Method: http.MethodPut,
+			Path:   s.migrationLockPath(),
+			Params: lock.params(),
+			Body:   migrationLock{Owner: lock.Owner, HeartbeatAt: time.Now().UnixNano() / int64(time.Millisecond)},
+		})
+		if es.IsConflict(err) || es.IsNotFound(err) {
+			s.logger.Errorf("lost the migration lock of index %s to another replica, stopping the migration",
+				s.index)
+			return false
+		}
+
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Errorf("failed updating the migration lock of index %s: %v", s.index, err)
+			}
+
+			continue
+		}
+
+		if err := json.Unmarshal(res.Body, lock); err != nil {
+			s.logger.Errorf("failed decoding the migration lock of index %s: %v", s.index, err)
+		}
+	}
+}
+
+// releaseMigrationLock deletes the migration lock held as lock, unless it was taken over by another replica.
+func (s Store) releaseMigrationLock(lock *heldMigrationLock) {
+	params := lock.params()
+	params.Set("refresh", "true")
+	_, err := s.client.PerformRequest(context.Background(), es.PerformRequestOptions{
+		Method: http.MethodDelete,
+		Path:   s.migrationLockPath(),
+		Params: params,
+	})
+	if es.IsConflict(err) || es.IsNotFound(err) {
+		s.logger.Warnf("the migration lock of index %s was taken over by another replica", s.index)
+		return
+	}
+
+	if err != nil {
+		s.logger.Errorf("failed releasing the migration lock of index %s: %v", s.index, err)
+	}
+}
+
+// migrationLockPath returns the path of the migration lock document of the store's index.
+func (s Store) migrationLockPath() string {
+	return fmt.Sprintf("/%s/_doc/%s", url.PathEscape(migrationLockIndex(s.baseIndex)), url.PathEscape(s.index))
+}
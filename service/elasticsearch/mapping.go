@@ -0,0 +1,287 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MappingDriftPolicy is what the store does at startup when the live index mappings
+// or settings drifted from the store's index settings.
+type MappingDriftPolicy string
+
+const (
+	// MappingDriftWarn logs the drift and starts normally.
+	MappingDriftWarn MappingDriftPolicy = "warn"
+
+	// MappingDriftFail refuses to start.
+	MappingDriftFail MappingDriftPolicy = "fail"
+
+	// MappingDriftApply adds the missing fields to the live mapping with put-mapping,
+	// and logs the drift that can't be applied without a reindex.
+	MappingDriftApply MappingDriftPolicy = "apply"
+)
+
+// MappingDiff is a difference between the expected and the live index mappings or settings.
+// Actual is nil when the live index has no such value.
+type MappingDiff struct {
+	Index    string
+	Path     []string
+	Expected interface{}
+	Actual   interface{}
+}
+
+// String returns a readable description of the difference.
+func (d MappingDiff) String() string {
+	if d.Actual == nil {
+		return fmt.Sprintf("%s %s: missing, expected %v", d.Index, strings.Join(d.Path, "."), d.Expected)
+	}
+
+	return fmt.Sprintf("%s %s: expected %v, found %v", d.Index, strings.Join(d.Path, "."), d.Expected, d.Actual)
+}
+
+// additive returns true if the difference is a field missing from the live mapping,
+// which can be added without a reindex.
+func (d MappingDiff) additive() bool {
+	return d.Actual == nil &&
+		len(d.Path) >= 3 &&
+		d.Path[0] == "mappings" &&
+		d.Path[len(d.Path)-2] == "properties"
+}
+
+// MappingDrift compares the mappings and analysis settings of the indices behind the index alias
+// of the tenant of the request in ctx, or of all tenants if ctx has none, with the store's
+// index settings, and returns the differences found, sorted by path.
+// Fields and settings that exist only in the live indices, such as dynamically mapped fields,
+// are not considered drift.
+func (s Store) MappingDrift(ctx context.Context) ([]MappingDiff, error) {
+	stores, err := s.tenantStores(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make([]MappingDiff, 0)
+	for _, store := range stores {
+		storeDiffs, err := store.mappingDrift(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		diffs = append(diffs, storeDiffs...)
+	}
+
+	return diffs, nil
+}
+
+// mappingDrift returns the drift of the store's index, see MappingDrift.
+func (s Store) mappingDrift(ctx context.Context) ([]MappingDiff, error) {
+	expected, err := s.indexBody()
+	if err != nil {
+		return nil, err
+	}
+
+	mappings, err := s.client.GetMapping().Index(s.index).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.client.IndexGetSettings(s.index).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedAnalysis := lookup(expected, "settings", "analysis")
+	diffs := make([]MappingDiff, 0)
+	for index, mapping := range mappings {
+		diffs = append(diffs, compare(
+			[]string{"mappings"},
+			expected["mappings"],
+			lookup(mapping, "mappings"),
+		)...)
+
+		if indexSettings, ok := settings[index]; ok && expectedAnalysis != nil {
+			diffs = append(diffs, compare(
+				[]string{"settings", "analysis"},
+				expectedAnalysis,
+				lookup(indexSettings.Settings, "index", "analysis"),
+			)...)
+		}
+	}
+
+	for i := range diffs {
+		diffs[i].Index = s.index
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return strings.Join(diffs[i].Path, ".") < strings.Join(diffs[j].Path, ".")
+	})
+
+	return dedupDiffs(diffs), nil
+}
+
+// checkMappingDrift detects the drift of the live index at startup, logs it,
+// and handles it according to s.driftPolicy.
+func (s Store) checkMappingDrift(ctx context.Context) error {
+	diffs, err := s.mappingDrift(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(diffs) == 0 {
+		return nil
+	}
+
+	s.logDrift(diffs)
+
+	switch s.driftPolicy {
+	case MappingDriftFail:
+		return fmt.Errorf("index %s mapping drifted from the expected mapping in %d places", s.index, len(diffs))
+	case MappingDriftApply:
+		return s.applyAdditiveDrift(ctx, diffs)
+	default:
+		return nil
+	}
+}
+
+// applyAdditiveDrift adds the fields missing from the live mapping using put-mapping.
+func (s Store) applyAdditiveDrift(ctx context.Context, diffs []MappingDiff) error {
+	properties := make(map[string]interface{})
+	applied := 0
+	for _, diff := range diffs {
+		if !diff.additive() {
+			continue
+		}
+
+		// Build the nested properties object leading to the missing field.
+		current := properties
+		for _, key := range diff.Path[1 : len(diff.Path)-1] {
+			next, ok := current[key].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				current[key] = next
+			}
+
+			current = next
+		}
+
+		current[diff.Path[len(diff.Path)-1]] = diff.Expected
+		applied++
+	}
+
+	if applied == 0 {
+		return nil
+	}
+
+	res, err := s.client.PutMapping().Index(s.index).BodyJson(properties).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !res.Acknowledged {
+		return fmt.Errorf("failed applying mapping drift to index %s", s.index)
+	}
+
+	s.logger.WithField("index", s.index).Infof("added %d missing fields to the index mapping", applied)
+
+	return nil
+}
+
+// logDrift logs each of the differences as a structured entry.
+func (s Store) logDrift(diffs []MappingDiff) {
+	for _, diff := range diffs {
+		s.logger.WithFields(logrus.Fields{
+			"index":    diff.Index,
+			"path":     strings.Join(diff.Path, "."),
+			"expected": diff.Expected,
+			"actual":   diff.Actual,
+			"additive": diff.additive(),
+		}).Warn("index mapping drifted")
+	}
+}
+
+// compare returns the differences between expected and actual, only considering the values
+// that exist in expected. Scalars are compared by their string representation since
+// elasticsearch returns settings values as strings.
+func compare(path []string, expected interface{}, actual interface{}) []MappingDiff {
+	// An empty object, such as the properties of an object mapping, has no values to compare,
+	// and isn't returned by elasticsearch.
+	if expectedValue, ok := expected.(map[string]interface{}); ok && len(expectedValue) == 0 {
+		return nil
+	}
+
+	if actual == nil {
+		return []MappingDiff{{Path: path, Expected: expected}}
+	}
+
+	switch expectedValue := expected.(type) {
+	case map[string]interface{}:
+		actualValue, ok := actual.(map[string]interface{})
+		if !ok {
+			return []MappingDiff{{Path: path, Expected: expected, Actual: actual}}
+		}
+
+		diffs := make([]MappingDiff, 0)
+		for key, value := range expectedValue {
+			keyPath := append(append([]string{}, path...), key)
+			diffs = append(diffs, compare(keyPath, value, actualValue[key])...)
+		}
+
+		return diffs
+	case []interface{}:
+		actualValue, ok := actual.([]interface{})
+		if !ok || !reflect.DeepEqual(stringify(expectedValue), stringify(actualValue)) {
+			return []MappingDiff{{Path: path, Expected: expected, Actual: actual}}
+		}
+
+		return nil
+	default:
+		if fmt.Sprint(expected) != fmt.Sprint(actual) {
+			return []MappingDiff{{Path: path, Expected: expected, Actual: actual}}
+		}
+
+		return nil
+	}
+}
+
+// stringify returns the string representations of values.
+func stringify(values []interface{}) []string {
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		strs = append(strs, fmt.Sprint(value))
+	}
+
+	return strs
+}
+
+// lookup returns the value at the path of keys in nested maps, or nil if there's none.
+func lookup(value interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		value = m[key]
+	}
+
+	return value
+}
+
+// dedupDiffs removes repeated differences from sorted diffs, found when several indices
+// are behind the index alias.
+func dedupDiffs(diffs []MappingDiff) []MappingDiff {
+	deduped := make([]MappingDiff, 0, len(diffs))
+	for i, diff := range diffs {
+		if i > 0 && reflect.DeepEqual(diff, diffs[i-1]) {
+			continue
+		}
+
+		deduped = append(deduped, diff)
+	}
+
+	return deduped
+}
@@ -0,0 +1,166 @@
+package elasticsearch
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	expected := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"name":     map[string]interface{}{"type": "text", "analyzer": "autocomplete"},
+			"size":     map[string]interface{}{"type": "long"},
+			"metadata": map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+		},
+		"dynamic_date_formats": []interface{}{"epoch_millis"},
+	}
+
+	tests := []struct {
+		name   string
+		actual interface{}
+		want   []MappingDiff
+	}{
+		{
+			name: "equal",
+			actual: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"name":     map[string]interface{}{"type": "text", "analyzer": "autocomplete"},
+					"size":     map[string]interface{}{"type": "long"},
+					"metadata": map[string]interface{}{"type": "object"},
+				},
+				"dynamic_date_formats": []interface{}{"epoch_millis"},
+			},
+		},
+		{
+			name: "live only fields",
+			actual: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"name":     map[string]interface{}{"type": "text", "analyzer": "autocomplete"},
+					"size":     map[string]interface{}{"type": "long"},
+					"metadata": map[string]interface{}{"type": "object"},
+					"dynamic":  map[string]interface{}{"type": "keyword"},
+				},
+				"dynamic_date_formats": []interface{}{"epoch_millis"},
+			},
+		},
+		{
+			name: "missing field",
+			actual: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"name":     map[string]interface{}{"type": "text", "analyzer": "autocomplete"},
+					"metadata": map[string]interface{}{"type": "object"},
+				},
+				"dynamic_date_formats": []interface{}{"epoch_millis"},
+			},
+			want: []MappingDiff{{
+				Path:     []string{"mappings", "properties", "size"},
+				Expected: map[string]interface{}{"type": "long"},
+			}},
+		},
+		{
+			name: "changed type and list",
+			actual: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"name":     map[string]interface{}{"type": "keyword", "analyzer": "autocomplete"},
+					"size":     map[string]interface{}{"type": "long"},
+					"metadata": map[string]interface{}{"type": "object"},
+				},
+				"dynamic_date_formats": []interface{}{"strict_date_optional_time"},
+			},
+			want: []MappingDiff{
+				{
+					Path:     []string{"mappings", "dynamic_date_formats"},
+					Expected: []interface{}{"epoch_millis"},
+					Actual:   []interface{}{"strict_date_optional_time"},
+				},
+				{
+					Path:     []string{"mappings", "properties", "name", "type"},
+					Expected: "text",
+					Actual:   "keyword",
+				},
+			},
+		},
+		{
+			name:   "missing mapping",
+			actual: nil,
+			want:   []MappingDiff{{Path: []string{"mappings"}, Expected: expected}},
+		},
+		{
+			name:   "mapping not an object",
+			actual: "mapping",
+			want:   []MappingDiff{{Path: []string{"mappings"}, Expected: expected, Actual: "mapping"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := compare([]string{"mappings"}, expected, tt.actual)
+			sort.Slice(got, func(i, j int) bool {
+				return strings.Join(got[i].Path, ".") < strings.Join(got[j].Path, ".")
+			})
+
+			if len(got) != len(tt.want) || len(got) > 0 && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("compare() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareSettingsStrings(t *testing.T) {
+	expected := map[string]interface{}{"min_gram": 2, "token_chars": []interface{}{"letter", "digit"}}
+	actual := map[string]interface{}{"min_gram": "2", "token_chars": []interface{}{"letter", "digit"}}
+	if got := compare([]string{"settings"}, expected, actual); len(got) != 0 {
+		t.Errorf("compare() = %v, want no differences", got)
+	}
+}
+
+func TestMappingDiffAdditive(t *testing.T) {
+	tests := []struct {
+		diff MappingDiff
+		want bool
+	}{
+		{
+			diff: MappingDiff{Path: []string{"mappings", "properties", "size"}},
+			want: true,
+		},
+		{
+			diff: MappingDiff{Path: []string{"mappings", "properties", "metadata", "properties", "key"}},
+			want: true,
+		},
+		{
+			diff: MappingDiff{Path: []string{"mappings", "properties", "name", "type"}, Actual: "keyword"},
+			want: false,
+		},
+		{
+			diff: MappingDiff{Path: []string{"mappings", "properties", "name", "analyzer"}},
+			want: false,
+		},
+		{
+			diff: MappingDiff{Path: []string{"settings", "analysis", "analyzer"}},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.diff.additive(); got != tt.want {
+			t.Errorf("%v additive() = %v, want %v", tt.diff, got, tt.want)
+		}
+	}
+}
+
+func TestDedupDiffs(t *testing.T) {
+	diffs := []MappingDiff{
+		{Index: "files", Path: []string{"mappings", "properties", "name"}, Expected: "text"},
+		{Index: "files", Path: []string{"mappings", "properties", "name"}, Expected: "text"},
+		{Index: "files", Path: []string{"mappings", "properties", "size"}, Expected: "long"},
+		{Index: "files", Path: []string{"mappings", "properties", "size"}, Expected: "long"},
+	}
+
+	want := []MappingDiff{diffs[0], diffs[2]}
+	if got := dedupDiffs(diffs); !reflect.DeepEqual(got, want) {
+		t.Errorf("dedupDiffs() = %v, want %v", got, want)
+	}
+}
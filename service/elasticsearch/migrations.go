@@ -0,0 +1,230 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+
+	es "github.com/olivere/elastic/v7"
+)
+
+// fieldSchemaVersion is the name of the index mapping _meta field holding the number
+// of the last migration applied to the index.
+const fieldSchemaVersion = "schema_version"
+
+// Migration is a numbered change to the index mappings, settings or files.
+// Migrations must be idempotent, since a migration interrupted before its version
+// is recorded is applied again.
+type Migration struct {
+	// Version is the number of the migration, migrations are applied in increasing order.
+	Version int
+
+	// Description describes the change the migration makes.
+	Description string
+
+	// Apply applies the migration to the store's index.
+	Apply func(ctx context.Context, s Store) error
+}
+
+// migrations are the changes made to the index since it was first created, in order.
+// IndexSettings must always reflect the result of applying all of them, since a newly
+// created index starts at the latest version.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "replace underscores in the names of files indexed before names were formatted",
+		Apply: backfill(
+			es.NewWildcardQuery("name.keyword", "*_*"),
+			"ctx._source.name = ctx._source.name.replace('_', ' ')",
+		),
+	},
+	{
+		Version:     2,
+		Description: "add the trashed and deletedAt fields",
+		Apply: putMapping(map[string]interface{}{
+			fieldTrashed:   map[string]interface{}{"type": "boolean"},
+			fieldDeletedAt: map[string]interface{}{"type": "long"},
+		}),
+	},
+	{
+		Version: 3,
+		Description: "index permissions as nested objects, " +
+			"requires a reindex since they may have been dynamically mapped as plain objects",
+		Apply: reindex(),
+	},
+	{
+		Version:     4,
+		Description: "add the tags and metadata fields",
+		Apply: putMapping(map[string]interface{}{
+			fieldTags:     map[string]interface{}{"type": "keyword"},
+			fieldMetadata: map[string]interface{}{"type": "flattened"},
+		}),
+	},
+}
+
+// latestSchemaVersion returns the version of the last migration.
+func latestSchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+
+	return migrations[len(migrations)-1].Version
+}
+
+// putMapping returns a migration adding properties to the index mapping, adapted to the cluster.
+func putMapping(properties map[string]interface{}) func(ctx context.Context, s Store) error {
+	return func(ctx context.Context, s Store) error {
+		adapted, templates := s.compatProperties(properties)
+		body := map[string]interface{}{"properties": adapted}
+		if len(templates) > 0 {
+			body["dynamic_templates"] = templates
+		}
+
+		res, err := s.client.PutMapping().
+			Index(s.index).
+			BodyJson(body).
+			Do(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !res.Acknowledged {
+			return fmt.Errorf("failed putting mapping to index %s", s.index)
+		}
+
+		return nil
+	}
+}
+
+// reindex returns a migration rebuilding the index with the store's index settings,
+// used for changes that can't be applied to an existing index such as analyzer changes.
+func reindex() func(ctx context.Context, s Store) error {
+	return func(ctx context.Context, s Store) error {
+		res, err := s.reindex(ctx)
+		if err != nil {
+			return err
+		}
+
+		s.logger.Infof("reindexed %d files from %s to %s", res.Total, res.Source, res.Destination)
+
+		return nil
+	}
+}
+
+// backfill returns a migration running a painless script on the files matching query.
+func backfill(query es.Query, script string) func(ctx context.Context, s Store) error {
+	return func(ctx context.Context, s Store) error {
+		res, err := s.client.UpdateByQuery(s.index).
+			Query(query).
+			Script(es.NewScript(script)).
+			ProceedOnVersionConflict().
+			WaitForCompletion(true).
+			Do(ctx)
+		if err != nil {
+			return err
+		}
+
+		s.logger.Infof("backfilled %d files", res.Updated)
+
+		return nil
+	}
+}
+
+// Migrate applies the migrations newer than the index's schema version in order,
+// recording the version in the index mapping _meta after each one.
+// The index is migrated by a single replica of the service at a time, holding its migration lock,
+// while the others wait for it and then find the index migrated.
+// Returns the schema versions before and after migrating, and any error if occurred.
+func (s Store) Migrate(ctx context.Context) (int, int, error) {
+	s, err := s.tenantStore(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return s.migrate(ctx)
+}
+
+// migrate migrates the store's index, see Migrate.
+func (s Store) migrate(ctx context.Context) (int, int, error) {
+	ctx, unlock, err := s.lockMigrations(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	defer unlock()
+
+	from, err := s.schemaVersion(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	current := from
+	for _, migration := range migrations {
+		if migration.Version <= current {
+			continue
+		}
+
+		s.logger.Infof("applying index migration %d: %s", migration.Version, migration.Description)
+		if err := migration.Apply(ctx, s); err != nil {
+			return from, current, fmt.Errorf("failed applying index migration %d: %v", migration.Version, err)
+		}
+
+		if err := s.setSchemaVersion(ctx, migration.Version); err != nil {
+			return from, current, err
+		}
+
+		current = migration.Version
+	}
+
+	return from, current, nil
+}
+
+// schemaVersion returns the schema version recorded in the mapping of the index files are written to.
+// An index created before migrations were introduced has no version, and is at version 0.
+func (s Store) schemaVersion(ctx context.Context) (int, error) {
+	index, err := s.resolveWriteIndex(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	mappings, err := s.client.GetMapping().Index(index).Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if version, ok := lookup(mappings[index], "mappings", "_meta", fieldSchemaVersion).(float64); ok {
+		return int(version), nil
+	}
+
+	return 0, nil
+}
+
+// setSchemaVersion records version as the schema version of the index files are written to.
+func (s Store) setSchemaVersion(ctx context.Context, version int) error {
+	index, err := s.resolveWriteIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.client.PutMapping().
+		Index(index).
+		BodyJson(schemaVersionMeta(version)).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !res.Acknowledged {
+		return fmt.Errorf("failed setting schema version of index %s", index)
+	}
+
+	return nil
+}
+
+// schemaVersionMeta returns the mapping _meta recording version as the schema version.
+func schemaVersionMeta(version int) map[string]interface{} {
+	return map[string]interface{}{
+		"_meta": map[string]interface{}{
+			fieldSchemaVersion: version,
+		},
+	}
+}
@@ -0,0 +1,198 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	es "github.com/olivere/elastic/v7"
+)
+
+const (
+	// reindexCheckInterval is how long a store caches whether a reindex of its index is running.
+	// A reindex waits twice as long before copying the files, so by then the writes of all of
+	// the service's replicas are mirrored to its destination.
+	reindexCheckInterval = 5 * time.Second
+
+	// reindexGCDeletes is the `index.gc_deletes` of a reindex destination while the files are copied to it,
+	// keeping the tombstones of the mirrored deletions so the copy doesn't recreate the deleted files.
+	reindexGCDeletes = "12h"
+)
+
+// reindexAlias returns the name of the alias pointing at the destination of the reindex running on index.
+func reindexAlias(index string) string {
+	return index + "_reindex"
+}
+
+// reindexTargets caches the destinations of the reindexes running on the store's indices,
+// shared by the copies of the store.
+type reindexTargets struct {
+	mu      sync.Mutex
+	targets map[string]reindexTarget
+}
+
+// reindexTarget is the destination of the reindex running on an index, or empty if none is running,
+// as checked at checkedAt.
+type reindexTarget struct {
+	destination string
+	checkedAt   time.Time
+}
+
+// reindexDestination returns the destination of the reindex running on the store's index,
+// or an empty string if none is running.
+func (s Store) reindexDestination(ctx context.Context) (string, error) {
+	s.reindexes.mu.Lock()
+	target, ok := s.reindexes.targets[s.index]
+	s.reindexes.mu.Unlock()
+	if ok && time.Since(target.checkedAt) < reindexCheckInterval {
+		return target.destination, nil
+	}
+
+	destinations, err := s.resolveIndices(ctx, reindexAlias(s.index))
+	if err != nil && !es.IsNotFound(err) {
+		return "", err
+	}
+
+	target = reindexTarget{checkedAt: time.Now()}
+	if len(destinations) > 0 {
+		target.destination = destinations[0]
+	}
+
+	s.reindexes.mu.Lock()
+	s.reindexes.targets[s.index] = target
+	s.reindexes.mu.Unlock()
+
+	return target.destination, nil
+}
+
+// mirroredRouting returns the routing a file owned by ownerID and indexed with routing has in the
+// destination of a reindex, which routes the files by their ownerID when owner routing is enabled,
+// see reindexScript.
+func (s Store) mirroredRouting(ownerID string, routing string) string {
+	if s.ownerRouting == OwnerRoutingNone || ownerID == "" {
+		return routing
+	}
+
+	return ownerID
+}
+
+// mirrorWrite copies the file with the given id, which was just written to index with routing,
+// to destination. It's indexed with its version in index as an external version, like the files
+// copied by the reindex, so whichever of them is newer is kept.
+// Does nothing if destination is empty or is index itself.
+func (s Store) mirrorWrite(
+	ctx context.Context,
+	destination string,
+	index string,
+	id string,
+	routing string,
+) error {
+	if destination == "" || destination == index {
+		return nil
+	}
+
+	res, err := s.client.Get().Index(index).Id(id).Routing(routing).Do(ctx)
+	if es.IsNotFound(err) {
+		// The file was deleted meanwhile, which is mirrored by its deletion.
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	ownerID, err := decodeOwnerID(res.Source)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Index().
+		Index(destination).
+		Id(id).
+		Routing(s.mirroredRouting(ownerID, routing)).
+		VersionType("external").
+		Version(*res.Version).
+		BodyString(string(res.Source)).
+		Do(ctx)
+	if es.IsConflict(err) {
+		// The destination already has this version of the file or a newer one.
+		return nil
+	}
+
+	return err
+}
+
+// mirroredDeleteRouting returns the routing in destination of the file with the given id at location,
+// which is read before the file is deleted since it's routed by its ownerID when owner routing is enabled.
+// Returns the file's routing at location if destination is empty or owner routing is disabled.
+func (s Store) mirroredDeleteRouting(
+	ctx context.Context,
+	destination string,
+	location fileLocation,
+	id string,
+) (string, error) {
+	if destination == "" || s.ownerRouting == OwnerRoutingNone {
+		return location.routing, nil
+	}
+
+	res, err := s.client.Get().Index(location.index).Id(id).Routing(location.routing).Do(ctx)
+	if es.IsNotFound(err) {
+		return location.routing, nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	ownerID, err := decodeOwnerID(res.Source)
+	if err != nil {
+		return "", err
+	}
+
+	return s.mirroredRouting(ownerID, location.routing), nil
+}
+
+// mirrorDelete deletes the file with the given id, which was just deleted from index at version,
+// from destination with routing. The deletion's version is kept as an external version, so the
+// reindex doesn't copy older versions of the file over it.
+// Does nothing if destination is empty or is index itself.
+func (s Store) mirrorDelete(
+	ctx context.Context,
+	destination string,
+	index string,
+	id string,
+	routing string,
+	version int64,
+) error {
+	if destination == "" || destination == index {
+		return nil
+	}
+
+	_, err := s.client.Delete().
+		Index(destination).
+		Id(id).
+		Routing(routing).
+		VersionType("external").
+		Version(version).
+		Do(ctx)
+	if es.IsNotFound(err) || es.IsConflict(err) {
+		// The file wasn't copied yet, which the deletion's tombstone prevents,
+		// or the destination already has a newer version of it.
+		return nil
+	}
+
+	return err
+}
+
+// decodeOwnerID returns the ownerID of the file document source.
+func decodeOwnerID(source []byte) (string, error) {
+	var file struct {
+		OwnerID string `json:"ownerID"`
+	}
+	if err := json.Unmarshal(source, &file); err != nil {
+		return "", err
+	}
+
+	return file.OwnerID, nil
+}
@@ -0,0 +1,59 @@
+package elasticsearch
+
+import (
+	"github.com/meateam/search-service/service"
+	es "github.com/olivere/elastic/v7"
+)
+
+// searchQuery returns the elasticsearch query matching the files of query.
+func searchQuery(query service.Query) es.Query {
+	boolQuery := es.NewBoolQuery().Must(es.NewMultiMatchQuery(query.Term))
+
+	switch query.Trashed {
+	case service.TrashedOnly:
+		boolQuery = boolQuery.Filter(es.NewTermQuery(fieldTrashed, true))
+	case service.TrashedExclude:
+		boolQuery = boolQuery.MustNot(es.NewTermQuery(fieldTrashed, true))
+	}
+
+	// Scope the results to the files the user owns or was granted access to.
+	if query.UserID != "" {
+		boolQuery = boolQuery.Filter(accessQuery(query.UserID, query.GroupIDs))
+	}
+
+	if query.OwnerID != "" {
+		boolQuery = boolQuery.Filter(es.NewTermQuery(fieldOwnerID, query.OwnerID))
+	}
+
+	for _, tag := range query.Tags {
+		boolQuery = boolQuery.Filter(es.NewTermQuery(fieldTags, tag))
+	}
+
+	for key, value := range query.Metadata {
+		boolQuery = boolQuery.Filter(es.NewTermQuery(fieldMetadata+"."+key, value))
+	}
+
+	return boolQuery
+}
+
+// accessQuery returns a query matching the files owned by userID, or shared with
+// userID or with any of groupIDs.
+func accessQuery(userID string, groupIDs []string) es.Query {
+	query := es.NewBoolQuery().
+		Should(
+			es.NewTermQuery(fieldOwnerID, userID),
+			es.NewNestedQuery(fieldPermissions, es.NewTermQuery(fieldPermissionsUserID, userID)),
+		).
+		MinimumNumberShouldMatch(1)
+
+	if len(groupIDs) > 0 {
+		groups := make([]interface{}, 0, len(groupIDs))
+		for _, groupID := range groupIDs {
+			groups = append(groups, groupID)
+		}
+
+		query = query.Should(es.NewNestedQuery(fieldPermissions, es.NewTermsQuery(fieldPermissionsGroupID, groups...)))
+	}
+
+	return query
+}
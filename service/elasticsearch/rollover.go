@@ -0,0 +1,158 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// generationPattern matches a rollover generation of a versioned index, i.e `files_v1-000002`.
+var generationPattern = regexp.MustCompile(`^(.+_v\d+)-(\d+)$`)
+
+// RolloverResult is the outcome of checking the rollover conditions of the write index.
+type RolloverResult struct {
+	// Index is the index alias whose write index was checked.
+	Index string
+
+	// OldIndex is the write index before the rollover.
+	OldIndex string
+
+	// NewIndex is the write index after the rollover, or the index it would have rolled over to.
+	NewIndex string
+
+	// RolledOver is true if any of the conditions were met and the write index rolled over.
+	RolledOver bool
+}
+
+// rolloverEnabled returns true if the write index is rolled over once it's too large.
+func (s Store) rolloverEnabled() bool {
+	return s.rolloverMaxDocs > 0 || s.rolloverMaxSize != ""
+}
+
+// rolloverTemplate returns the name of the index template of the store's rollover generations.
+func (s Store) rolloverTemplate() string {
+	return s.index + "_rollover"
+}
+
+// ensureRolloverTemplate puts the index template the rollover generations of the store's index
+// are created from, so that a new generation has the same settings and mappings as the old one.
+// The template has no aliases, since the index alias is only added to a new versioned index
+// once it's fully reindexed.
+func (s Store) ensureRolloverTemplate(ctx context.Context) error {
+	if !s.rolloverEnabled() {
+		return nil
+	}
+
+	body, err := s.indexBody()
+	if err != nil {
+		return err
+	}
+
+	body["index_patterns"] = []string{s.index + "_v*"}
+
+	// Take precedence over the tenants' index template, which also matches the tenants' generations.
+	body["order"] = 1
+
+	res, err := s.client.IndexPutTemplate(s.rolloverTemplate()).BodyJson(body).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !res.Acknowledged {
+		return fmt.Errorf("failed putting rollover index template for %s", s.index)
+	}
+
+	return nil
+}
+
+// Rollover rolls the write index over to a new generation of the index if it meets any of the
+// rollover conditions, for the tenant of the request in ctx, or for all tenants if ctx has none.
+// The new generation is added to the index alias, so files are still read from all generations.
+// If successful returns the result of each index and a nil error,
+// otherwise returns the results so far and non-nil error if any occurred.
+func (s Store) Rollover(ctx context.Context) ([]RolloverResult, error) {
+	if !s.rolloverEnabled() {
+		return nil, nil
+	}
+
+	stores, err := s.tenantStores(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]RolloverResult, 0, len(stores))
+	for _, store := range stores {
+		result, err := store.rollover(ctx)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, *result)
+	}
+
+	return results, nil
+}
+
+// rollover rolls over the write index of the store's index, see Rollover.
+func (s Store) rollover(ctx context.Context) (*RolloverResult, error) {
+	current, err := s.resolveWriteIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The index alias can't be added to a new generation while an index has its name.
+	if current == s.index {
+		return nil, fmt.Errorf("index %s must be reindexed before it can be rolled over", s.index)
+	}
+
+	// The new generation continues the schema version of the current one, which the template lacks.
+	schemaVersion, err := s.schemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := make(map[string]interface{})
+	if s.rolloverMaxDocs > 0 {
+		conditions["max_docs"] = s.rolloverMaxDocs
+	}
+
+	if s.rolloverMaxSize != "" {
+		conditions["max_size"] = s.rolloverMaxSize
+	}
+
+	res, err := s.client.RolloverIndex(s.writeIndex).
+		NewIndex(s.nextGeneration(current)).
+		BodyJson(map[string]interface{}{
+			"conditions": conditions,
+			"mappings":   schemaVersionMeta(schemaVersion),
+			"aliases":    map[string]interface{}{s.index: map[string]interface{}{}},
+		}).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RolloverResult{
+		Index:      s.index,
+		OldIndex:   res.OldIndex,
+		NewIndex:   res.NewIndex,
+		RolledOver: res.RolledOver,
+	}, nil
+}
+
+// nextGeneration returns the name of the rollover generation following index.
+// A versioned index created before rollover was enabled is followed by the second generation of its version.
+func (s Store) nextGeneration(index string) string {
+	match := generationPattern.FindStringSubmatch(index)
+	if match == nil {
+		return fmt.Sprintf("%s-%06d", versionedIndex(s.index, indexVersion(index)), 2)
+	}
+
+	generation, err := strconv.Atoi(match[2])
+	if err != nil {
+		return fmt.Sprintf("%s-%06d", match[1], 2)
+	}
+
+	return fmt.Sprintf("%s-%06d", match[1], generation+1)
+}
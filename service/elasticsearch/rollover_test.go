@@ -0,0 +1,59 @@
+package elasticsearch
+
+import (
+	"testing"
+)
+
+func TestNextGeneration(t *testing.T) {
+	tests := []struct {
+		index string
+		want  string
+	}{
+		{index: "files_v1-000001", want: "files_v1-000002"},
+		{index: "files_v1-000009", want: "files_v1-000010"},
+		{index: "files_v3-000041", want: "files_v3-000042"},
+		{index: "files_v2", want: "files_v2-000002"},
+	}
+
+	s := Store{index: "files", rolloverMaxDocs: 1}
+	for _, tt := range tests {
+		if got := s.nextGeneration(tt.index); got != tt.want {
+			t.Errorf("nextGeneration(%q) = %q, want %q", tt.index, got, tt.want)
+		}
+	}
+}
+
+func TestNewIndex(t *testing.T) {
+	tests := []struct {
+		name  string
+		store Store
+		want  string
+	}{
+		{name: "rollover disabled", store: Store{index: "files"}, want: "files_v2"},
+		{name: "rollover by docs", store: Store{index: "files", rolloverMaxDocs: 1000}, want: "files_v2-000001"},
+		{name: "rollover by size", store: Store{index: "files", rolloverMaxSize: "50gb"}, want: "files_v2-000001"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.store.newIndex(2); got != tt.want {
+			t.Errorf("%s newIndex() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGenerationVersion(t *testing.T) {
+	tests := []struct {
+		index string
+		want  int
+	}{
+		{index: "files_v1-000001", want: 1},
+		{index: "files_v3-000042", want: 3},
+		{index: "files-acme_v2-000002", want: 2},
+	}
+
+	for _, tt := range tests {
+		if got := indexVersion(tt.index); got != tt.want {
+			t.Errorf("indexVersion(%q) = %d, want %d", tt.index, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	pb "github.com/meateam/search-service/proto"
+	"github.com/meateam/search-service/service"
+	es "github.com/olivere/elastic/v7"
+)
+
+// OwnerRouting is how files are routed to shards by their ownerID, so that the files of
+// an owner are all in a single shard and the owner's searches only hit that shard.
+type OwnerRouting string
+
+const (
+	// OwnerRoutingNone routes files by their id.
+	OwnerRoutingNone OwnerRouting = "none"
+
+	// OwnerRoutingWrite routes new files by their ownerID without routing the searches,
+	// while the existing files are reindexed to be routed by their ownerID.
+	OwnerRoutingWrite OwnerRouting = "write"
+
+	// OwnerRoutingFull routes new files by their ownerID and routes owner-scoped searches
+	// to the owner's shard. Should only be set once all of the files are routed by their ownerID.
+	OwnerRoutingFull OwnerRouting = "full"
+)
+
+// reindexRoutingScript routes the reindexed files by their ownerID.
+const reindexRoutingScript = "if (ctx._source.ownerID != null) { ctx._routing = ctx._source.ownerID }"
+
+// validateOwnerRouting validates the owner routing is one of the OwnerRouting values.
+func validateOwnerRouting(routing OwnerRouting) error {
+	switch routing {
+	case OwnerRoutingNone, OwnerRoutingWrite, OwnerRoutingFull:
+		return nil
+	default:
+		return fmt.Errorf("invalid owner routing: %s", routing)
+	}
+}
+
+// createRouting returns the routing a new file owned by ownerID is indexed with.
+func (s Store) createRouting(ownerID string) string {
+	if s.ownerRouting == OwnerRoutingNone {
+		return ""
+	}
+
+	return ownerID
+}
+
+// searchRouting returns the routing of a search for the files owned by ownerID.
+func (s Store) searchRouting(ownerID string) string {
+	if s.ownerRouting != OwnerRoutingFull {
+		return ""
+	}
+
+	return ownerID
+}
+
+// fileLocation is the physical index and the routing a file was indexed with.
+type fileLocation struct {
+	index   string
+	routing string
+}
+
+// searchByID returns true if files can't be found by their id alone, since they may be
+// routed by their owner or be in any of the rollover generations of the index.
+func (s Store) searchByID() bool {
+	return s.ownerRouting != OwnerRoutingNone || s.rolloverEnabled()
+}
+
+// locateRoutings returns the routings a file may have been indexed with, tried in order when locating it:
+// the routings of the owner hinted by ctx, see service.WithOwnerID, and of ownerID, and the id routing
+// of files indexed before owner routing was enabled.
+func (s Store) locateRoutings(ctx context.Context, ownerID string) []string {
+	if s.ownerRouting == OwnerRoutingNone {
+		return []string{""}
+	}
+
+	routings := make([]string, 0, 3)
+	if hint := service.OwnerIDFromContext(ctx); hint != "" {
+		routings = append(routings, hint)
+	}
+
+	if ownerID != "" && (len(routings) == 0 || routings[0] != ownerID) {
+		routings = append(routings, ownerID)
+	}
+
+	return append(routings, "")
+}
+
+// locate returns the physical index and the routing the file with the given id was indexed with.
+// The file is looked up with a realtime get in each of the indices behind the index alias with each of
+// the routings of locateRoutings, so a file is found right after it's written. Only if it's not found
+// there, i.e it's routed by a former owner that wasn't hinted, it's searched in all shards, which finds
+// it only once it's refreshed.
+// Returns the write alias and an empty routing if files can be found by their id alone,
+// or if the file is not found.
+func (s Store) locate(ctx context.Context, id string, ownerID string) (fileLocation, error) {
+	location := fileLocation{index: s.writeIndex}
+	if !s.searchByID() {
+		return location, nil
+	}
+
+	locations, err := s.getLocations(ctx, []string{id}, s.locateRoutings(ctx, ownerID))
+	if err != nil {
+		return location, err
+	}
+
+	if found, ok := locations[id]; ok {
+		return found, nil
+	}
+
+	res, err := s.client.Search().
+		Index(s.index).
+		Query(es.NewIdsQuery().Ids(id)).
+		FetchSource(false).
+		Size(1).
+		Do(ctx)
+	if err != nil {
+		return location, err
+	}
+
+	if len(res.Hits.Hits) == 0 {
+		return location, nil
+	}
+
+	hit := res.Hits.Hits[0]
+
+	return fileLocation{index: hit.Index, routing: hit.Routing}, nil
+}
+
+// getLocations looks up the files with the given ids with a realtime multi get in each of the indices
+// behind the index alias with each of routings, returns the locations of the files found by their id.
+func (s Store) getLocations(
+	ctx context.Context,
+	ids []string,
+	routings []string,
+) (map[string]fileLocation, error) {
+	indices, err := s.resolveIndices(ctx, s.index)
+	if err != nil {
+		return nil, err
+	}
+
+	// The client's multi get sends the routing of its items as `_routing`, which elasticsearch 7 rejects,
+	// so it's sent as a raw request.
+	docs := make([]map[string]interface{}, 0, len(ids)*len(indices)*len(routings))
+	for _, id := range ids {
+		for _, index := range indices {
+			for _, routing := range routings {
+				doc := map[string]interface{}{"_index": index, "_id": id, "_source": false}
+				if routing != "" {
+					doc["routing"] = routing
+				}
+
+				docs = append(docs, doc)
+			}
+		}
+	}
+
+	body, err := s.client.PerformRequest(ctx, es.PerformRequestOptions{
+		Method: http.MethodPost,
+		Path:   "/_mget",
+		Params: url.Values{"realtime": []string{"true"}},
+		Body:   map[string]interface{}{"docs": docs},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res es.MgetResponse
+	if err := json.Unmarshal(body.Body, &res); err != nil {
+		return nil, err
+	}
+
+	locations := make(map[string]fileLocation, len(ids))
+	for _, doc := range res.Docs {
+		if doc.Error != nil {
+			return nil, fmt.Errorf("failed getting file %s: %s", doc.Id, doc.Error.Reason)
+		}
+
+		if doc.Found {
+			locations[doc.Id] = fileLocation{index: doc.Index, routing: doc.Routing}
+		}
+	}
+
+	return locations, nil
+}
+
+// reindexScript returns the script applied to files when reindexing, or nil if there's none.
+func (s Store) reindexScript() *es.Script {
+	if s.ownerRouting == OwnerRoutingNone {
+		return nil
+	}
+
+	return es.NewScript(reindexRoutingScript)
+}
+
+// reroute moves the file at location to the shard of its new owner, since a file's routing can't be changed
+// in place: the stored file is merged with the update and indexed into the write index with the routing of
+// its new owner, and then its former copy is deleted, unless the moved file overwrote it in the same shard.
+// The moved file is indexed with an external version following the version of its former copy, so the
+// versions of its mirrored writes keep increasing.
+func (s Store) reroute(
+	ctx context.Context,
+	location fileLocation,
+	update *pb.File,
+	refresh pb.Refresh,
+	destination string,
+) (string, error) {
+	id := update.GetId()
+
+	// The file's sequence number isn't returned by the client's get, so it's read as a raw request
+	// to delete its former copy only if it wasn't overwritten by the moved file.
+	path := fmt.Sprintf("/%s/_doc/%s", url.PathEscape(location.index), url.PathEscape(id))
+	params := url.Values{}
+	if location.routing != "" {
+		params.Set("routing", location.routing)
+	}
+
+	res, err := s.client.PerformRequest(ctx, es.PerformRequestOptions{
+		Method: http.MethodGet,
+		Path:   path,
+		Params: params,
+	})
+	if err != nil {
+		return "", fileError(id, err)
+	}
+
+	var stored struct {
+		Version     int64           `json:"_version"`
+		SeqNo       int64           `json:"_seq_no"`
+		PrimaryTerm int64           `json:"_primary_term"`
+		Source      json.RawMessage `json:"_source"`
+	}
+	if err := json.Unmarshal(res.Body, &stored); err != nil {
+		return "", err
+	}
+
+	file, err := decodeFile(stored.Source)
+	if err != nil {
+		return "", err
+	}
+
+	formerOwnerID := file.GetOwnerID()
+	service.MergeFile(file, update)
+
+	routing := s.createRouting(file.GetOwnerID())
+	created, err := s.client.Index().
+		Index(s.writeIndex).
+		Id(id).
+		Routing(routing).
+		VersionType("external").
+		Version(stored.Version + 1).
+		BodyJson(file).
+		Refresh(s.refreshParam(refresh)).
+		Do(ctx)
+	if err != nil {
+		return "", storeError(err)
+	}
+
+	if err := s.mirrorWrite(ctx, destination, created.Index, id, routing); err != nil {
+		return "", storeError(err)
+	}
+
+	params.Set("if_seq_no", strconv.FormatInt(stored.SeqNo, 10))
+	params.Set("if_primary_term", strconv.FormatInt(stored.PrimaryTerm, 10))
+	params.Set("refresh", s.refreshParam(refresh))
+	res, err = s.client.PerformRequest(ctx, es.PerformRequestOptions{
+		Method: http.MethodDelete,
+		Path:   path,
+		Params: params,
+	})
+	if es.IsConflict(err) || es.IsNotFound(err) {
+		// The moved file overwrote its former copy in the same shard, or it was deleted meanwhile.
+		return created.Id, nil
+	}
+
+	if err != nil {
+		return "", storeError(err)
+	}
+
+	var deleted es.DeleteResponse
+	if err := json.Unmarshal(res.Body, &deleted); err != nil {
+		return "", err
+	}
+
+	// The deletion is mirrored after the moved file, so it doesn't delete it if both copies are in the
+	// same shard of the destination.
+	mirroredRouting := s.mirroredRouting(formerOwnerID, location.routing)
+	if err := s.mirrorDelete(ctx, destination, deleted.Index, id, mirroredRouting, deleted.Version); err != nil {
+		return "", storeError(err)
+	}
+
+	return created.Id, nil
+}
@@ -0,0 +1,103 @@
+package elasticsearch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/meateam/search-service/service"
+)
+
+func TestValidateOwnerRouting(t *testing.T) {
+	tests := []struct {
+		routing OwnerRouting
+		wantErr bool
+	}{
+		{routing: OwnerRoutingNone},
+		{routing: OwnerRoutingWrite},
+		{routing: OwnerRoutingFull},
+		{routing: "", wantErr: true},
+		{routing: "Full", wantErr: true},
+		{routing: "owner", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := validateOwnerRouting(tt.routing); (err != nil) != tt.wantErr {
+			t.Errorf("validateOwnerRouting(%q) error = %v, wantErr %v", tt.routing, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRoutings(t *testing.T) {
+	tests := []struct {
+		routing        OwnerRouting
+		wantCreate     string
+		wantSearch     string
+		wantSearchByID bool
+	}{
+		{routing: OwnerRoutingNone},
+		{routing: OwnerRoutingWrite, wantCreate: "owner", wantSearchByID: true},
+		{routing: OwnerRoutingFull, wantCreate: "owner", wantSearch: "owner", wantSearchByID: true},
+	}
+
+	for _, tt := range tests {
+		s := Store{ownerRouting: tt.routing}
+		if got := s.createRouting("owner"); got != tt.wantCreate {
+			t.Errorf("%s createRouting() = %q, want %q", tt.routing, got, tt.wantCreate)
+		}
+
+		if got := s.searchRouting("owner"); got != tt.wantSearch {
+			t.Errorf("%s searchRouting() = %q, want %q", tt.routing, got, tt.wantSearch)
+		}
+
+		if got := s.searchByID(); got != tt.wantSearchByID {
+			t.Errorf("%s searchByID() = %v, want %v", tt.routing, got, tt.wantSearchByID)
+		}
+	}
+}
+
+func TestLocateRoutings(t *testing.T) {
+	tests := []struct {
+		name    string
+		routing OwnerRouting
+		hint    string
+		ownerID string
+		want    []string
+	}{
+		{
+			name:    "owner routing disabled",
+			routing: OwnerRoutingNone,
+			hint:    "hint",
+			ownerID: "owner",
+			want:    []string{""},
+		},
+		{name: "no owner", routing: OwnerRoutingWrite, want: []string{""}},
+		{name: "owner", routing: OwnerRoutingWrite, ownerID: "owner", want: []string{"owner", ""}},
+		{name: "hint", routing: OwnerRoutingFull, hint: "hint", want: []string{"hint", ""}},
+		{
+			name:    "hint and former owner",
+			routing: OwnerRoutingFull,
+			hint:    "hint",
+			ownerID: "owner",
+			want:    []string{"hint", "owner", ""},
+		},
+		{
+			name:    "hint of the owner",
+			routing: OwnerRoutingFull,
+			hint:    "owner",
+			ownerID: "owner",
+			want:    []string{"owner", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			s := Store{ownerRouting: tt.routing}
+			got := s.locateRoutings(service.WithOwnerID(context.Background(), tt.hint), tt.ownerID)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("locateRoutings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// requiredFields are the mapped fields the store queries, which the index settings must map.
+var requiredFields = []string{
+	"name",
+	"ownerID",
+	fieldTrashed,
+	fieldDeletedAt,
+	fieldPermissions,
+	fieldTags,
+	fieldMetadata,
+}
+
+// LoadIndexSettings reads index settings and mappings from the JSON or YAML file at path,
+// chosen by the file's extension, and returns them as JSON.
+// The file is read directly rather than through viper, since viper lowercases keys,
+// which would break camelCase field names such as ownerID.
+func LoadIndexSettings(path string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed reading index settings: %v", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var settings interface{}
+		if err := yaml.Unmarshal(content, &settings); err != nil {
+			return "", fmt.Errorf("invalid index settings %s: %v", path, err)
+		}
+
+		settingsJSON, err := json.Marshal(yamlToJSON(settings))
+		if err != nil {
+			return "", fmt.Errorf("invalid index settings %s: %v", path, err)
+		}
+
+		return string(settingsJSON), nil
+	default:
+		return string(content), nil
+	}
+}
+
+// validateIndexSettings validates that settings is a JSON object with a mapping for each of
+// the required fields.
+func validateIndexSettings(settings string) error {
+	body := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(settings), &body); err != nil {
+		return fmt.Errorf("invalid index settings: %v", err)
+	}
+
+	properties, ok := lookup(body, "mappings", "properties").(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid index settings: mappings.properties is required")
+	}
+
+	for _, field := range requiredFields {
+		if _, ok := properties[field]; !ok {
+			return fmt.Errorf("invalid index settings: mappings.properties.%s is required", field)
+		}
+	}
+
+	if lookup(properties, fieldPermissions, "type") != "nested" {
+		return fmt.Errorf("invalid index settings: mappings.properties.%s must be nested", fieldPermissions)
+	}
+
+	return nil
+}
+
+// indexBody returns the store's index settings decoded, with their mappings adapted to the cluster.
+func (s Store) indexBody() (map[string]interface{}, error) {
+	body := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(s.settings), &body); err != nil {
+		return nil, fmt.Errorf("invalid index settings: %v", err)
+	}
+
+	mappings, ok := body["mappings"].(map[string]interface{})
+	if !ok {
+		return body, nil
+	}
+
+	properties, ok := mappings["properties"].(map[string]interface{})
+	if !ok {
+		return body, nil
+	}
+
+	properties, templates := s.compatProperties(properties)
+	mappings["properties"] = properties
+	if len(templates) > 0 {
+		existing, _ := mappings["dynamic_templates"].([]interface{})
+		mappings["dynamic_templates"] = append(existing, templates...)
+	}
+
+	return body, nil
+}
+
+// yamlToJSON converts the maps decoded by yaml, which are keyed by interface{},
+// to maps keyed by string so they can be encoded to JSON.
+func yamlToJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[fmt.Sprint(key)] = yamlToJSON(val)
+		}
+
+		return m
+	case []interface{}:
+		for i, val := range v {
+			v[i] = yamlToJSON(val)
+		}
+
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,108 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateIndexSettings(t *testing.T) {
+	// withProperties returns the default index settings with their mapped properties changed by change.
+	withProperties := func(change func(properties map[string]interface{})) string {
+		body := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(IndexSettings), &body); err != nil {
+			t.Fatalf("invalid default index settings: %v", err)
+		}
+
+		change(lookup(body, "mappings", "properties").(map[string]interface{}))
+		settings, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+
+		return string(settings)
+	}
+
+	tests := []struct {
+		name     string
+		settings string
+		wantErr  bool
+	}{
+		{name: "default", settings: IndexSettings},
+		{name: "invalid json", settings: `{"mappings":`, wantErr: true},
+		{name: "no mappings", settings: `{"settings": {}}`, wantErr: true},
+		{
+			name: "missing required field",
+			settings: withProperties(func(properties map[string]interface{}) {
+				delete(properties, "ownerID")
+			}),
+			wantErr: true,
+		},
+		{
+			name: "permissions not nested",
+			settings: withProperties(func(properties map[string]interface{}) {
+				properties[fieldPermissions] = map[string]interface{}{"type": "object"}
+			}),
+			wantErr: true,
+		},
+		{
+			name: "additional field",
+			settings: withProperties(func(properties map[string]interface{}) {
+				properties["department"] = map[string]interface{}{"type": "keyword"}
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateIndexSettings(tt.settings); (err != nil) != tt.wantErr {
+				t.Errorf("validateIndexSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadIndexSettings(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "settings.yaml")
+	content := "mappings:\n  properties:\n    ownerID:\n      type: keyword\n"
+	if err := ioutil.WriteFile(yamlPath, []byte(content), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	settings, err := LoadIndexSettings(yamlPath)
+	if err != nil {
+		t.Fatalf("LoadIndexSettings() error = %v", err)
+	}
+
+	// The camelCase field names must be kept as is.
+	want := `{"mappings":{"properties":{"ownerID":{"type":"keyword"}}}}`
+	if settings != want {
+		t.Errorf("LoadIndexSettings() = %s, want %s", settings, want)
+	}
+
+	jsonPath := filepath.Join(dir, "settings.json")
+	if err := ioutil.WriteFile(jsonPath, []byte(IndexSettings), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if settings, err := LoadIndexSettings(jsonPath); err != nil || settings != IndexSettings {
+		t.Errorf("LoadIndexSettings() = %.20s..., %v, want the file's content", settings, err)
+	}
+
+	if _, err := LoadIndexSettings(filepath.Join(dir, "missing.json")); err == nil {
+		t.Errorf("LoadIndexSettings() of a missing file error = nil, want an error")
+	}
+
+	invalidPath := filepath.Join(dir, "invalid.yml")
+	if err := ioutil.WriteFile(invalidPath, []byte("mappings: ["), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := LoadIndexSettings(invalidPath); err == nil || !strings.Contains(err.Error(), "invalid") {
+		t.Errorf("LoadIndexSettings() of invalid yaml error = %v, want an invalid index settings error", err)
+	}
+}
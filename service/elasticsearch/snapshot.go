@@ -0,0 +1,206 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/meateam/search-service/service"
+	es "github.com/olivere/elastic/v7"
+)
+
+// snapshotNamePattern matches the snapshot names accepted by elasticsearch.
+var snapshotNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// snapshotTimeFormat is the format of the time suffix of a snapshot named by the store.
+const snapshotTimeFormat = "2006.01.02-15.04.05"
+
+// RestoreResult is the outcome of restoring a snapshot.
+type RestoreResult struct {
+	// Alias is the alias the restored indices are behind.
+	Alias string
+
+	// Indices are the restored indices.
+	Indices []string
+}
+
+// ensureSnapshotRepository registers the store's filesystem snapshot repository.
+func (s Store) ensureSnapshotRepository(ctx context.Context) error {
+	if s.snapshotRepository == "" {
+		return nil
+	}
+
+	res, err := s.client.SnapshotCreateRepository(s.snapshotRepository).
+		Type("fs").
+		Setting("location", s.snapshotLocation).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !res.Acknowledged {
+		return fmt.Errorf("failed registering snapshot repository %s", s.snapshotRepository)
+	}
+
+	return nil
+}
+
+// CreateSnapshot snapshots the indices behind the index alias of the tenant of the request in ctx,
+// or of all tenants if ctx has none, waiting for the snapshot to complete.
+// The snapshot is named after the index and the current time if name is empty.
+// If successful returns the snapshot and a nil error,
+// otherwise returns nil and non-nil error if any occurred.
+func (s Store) CreateSnapshot(ctx context.Context, name string) (*es.Snapshot, error) {
+	if s.snapshotRepository == "" {
+		return nil, fmt.Errorf("snapshot repository is not configured: %w", service.ErrUnsupported)
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("%s-%s", s.baseIndex, time.Now().UTC().Format(snapshotTimeFormat))
+	}
+
+	if !snapshotNamePattern.MatchString(name) {
+		return nil, service.InvalidField("name", "invalid snapshot name: %s", name)
+	}
+
+	stores, err := s.tenantStores(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]string, 0, len(stores))
+	for _, store := range stores {
+		storeIndices, err := store.resolveIndices(ctx, store.index)
+		if err != nil {
+			return nil, err
+		}
+
+		indices = append(indices, storeIndices...)
+	}
+
+	res, err := s.client.SnapshotCreate(s.snapshotRepository, name).
+		BodyJson(map[string]interface{}{
+			"indices":              strings.Join(indices, ","),
+			"include_global_state": false,
+		}).
+		WaitForCompletion(true).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Snapshot == nil {
+		return nil, fmt.Errorf("snapshot %s was not created", name)
+	}
+
+	return res.Snapshot, nil
+}
+
+// ListSnapshots returns the snapshots in the store's snapshot repository.
+// If successful returns the snapshots and a nil error,
+// otherwise returns nil and non-nil error if any occurred.
+func (s Store) ListSnapshots(ctx context.Context) ([]*es.Snapshot, error) {
+	if s.snapshotRepository == "" {
+		return nil, fmt.Errorf("snapshot repository is not configured: %w", service.ErrUnsupported)
+	}
+
+	res, err := s.client.SnapshotGet(s.snapshotRepository).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Snapshots, nil
+}
+
+// RestoreSnapshot restores the indices of the tenant of the request in ctx from the snapshot
+// into new indices, suffixed by the snapshot name, behind alias, which defaults to `<index>_restored`.
+// The live aliases are left untouched, switching to the restored indices is done manually with
+// an alias swap once they were verified.
+// If successful returns the restored indices and a nil error,
+// otherwise returns nil and non-nil error if any occurred.
+func (s Store) RestoreSnapshot(ctx context.Context, name string, alias string) (*RestoreResult, error) {
+	if s.snapshotRepository == "" {
+		return nil, fmt.Errorf("snapshot repository is not configured: %w", service.ErrUnsupported)
+	}
+
+	if !snapshotNamePattern.MatchString(name) {
+		return nil, service.InvalidField("name", "invalid snapshot name: %s", name)
+	}
+
+	s, err := s.tenantStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if alias == "" {
+		alias = s.index + "_restored"
+	}
+
+	if alias == s.index || alias == s.writeIndex {
+		return nil, service.InvalidField("alias", "can't restore snapshot %s behind the live alias %s", name, alias)
+	}
+
+	res, err := s.client.SnapshotGet(s.snapshotRepository).Snapshot(name).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Snapshots) != 1 {
+		return nil, fmt.Errorf("snapshot %s: %w", name, service.ErrNotFound)
+	}
+
+	sources := make([]string, 0, len(res.Snapshots[0].Indices))
+	for _, index := range res.Snapshots[0].Indices {
+		if s.ownsIndex(index) {
+			sources = append(sources, index)
+		}
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("snapshot %s has no indices of %s", name, s.index)
+	}
+
+	// The restore API is not supported by the client, so it's performed as a raw request.
+	suffix := "_restored_" + name
+	_, err = s.client.PerformRequest(ctx, es.PerformRequestOptions{
+		Method: http.MethodPost,
+		Path: fmt.Sprintf("/_snapshot/%s/%s/_restore",
+			url.PathEscape(s.snapshotRepository), url.PathEscape(name)),
+		Params: url.Values{"wait_for_completion": []string{"true"}},
+		Body: map[string]interface{}{
+			"indices":              strings.Join(sources, ","),
+			"include_global_state": false,
+			"include_aliases":      false,
+			"rename_pattern":       "(.+)",
+			"rename_replacement":   "$1" + suffix,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	restored := make([]string, 0, len(sources))
+	for _, index := range sources {
+		restored = append(restored, index+suffix)
+	}
+
+	aliasRes, err := s.client.Alias().Action(es.NewAliasAddAction(alias).Index(restored...)).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !aliasRes.Acknowledged {
+		return nil, fmt.Errorf("failed adding alias %s to the indices restored from snapshot %s", alias, name)
+	}
+
+	return &RestoreResult{Alias: alias, Indices: restored}, nil
+}
+
+// ownsIndex returns true if index is the store's index or one of its versioned indices.
+func (s Store) ownsIndex(index string) bool {
+	return index == s.index || strings.HasPrefix(index, s.index+"_v")
+}
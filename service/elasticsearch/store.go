@@ -1,71 +1,419 @@
 package elasticsearch
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 
+	"github.com/golang/protobuf/jsonpb"
 	pb "github.com/meateam/search-service/proto"
+	"github.com/meateam/search-service/service"
 	es "github.com/olivere/elastic/v7"
+	"github.com/sirupsen/logrus"
 )
 
 // Store holds the elasticsearch and implements Store interface.
+// Files are read through the index alias and written through its write alias,
+// both pointing at a versioned index, i.e `files_v1`.
+// When rollover is enabled, the index alias points at the rollover generations of the versioned index,
+// i.e `files_v1-000001`, and the write alias at the latest one.
+// When tenants are configured, each tenant has its own index, i.e `files-<tenant>`,
+// and the store's methods operate on the index of the tenant of the request.
+// While the index is reindexed, the writes are also mirrored to the new index, see Reindex.
 type Store struct {
-	client *es.Client
-	index  string
+	client           *es.Client
+	baseIndex        string
+	index            string
+	writeIndex       string
+	tenants          []string
+	ownerRouting     OwnerRouting
+	settings         string
+	logger           *logrus.Logger
+	driftPolicy      MappingDriftPolicy
+	migrateOnStartup bool
+	rolloverMaxDocs  int64
+	rolloverMaxSize  string
+	refresh          pb.Refresh
+
+	snapshotRepository string
+	snapshotLocation   string
+
+	// cluster is the distribution and version of the cluster, detected at startup.
+	cluster ClusterInfo
+
+	// reindexes caches the destinations of the running reindexes, which writes are mirrored to.
+	reindexes *reindexTargets
+}
+
+// Option configures optional behavior of the Store.
+type Option func(*Store)
+
+// WithLogger sets the logger the store reports the state of its index to.
+// Defaults to the standard logrus logger.
+func WithLogger(logger *logrus.Logger) Option {
+	return func(s *Store) {
+		s.logger = logger
+	}
+}
+
+// WithMappingDriftPolicy sets how the store handles a drift of the live index mappings at startup.
+// Defaults to MappingDriftWarn.
+func WithMappingDriftPolicy(policy MappingDriftPolicy) Option {
+	return func(s *Store) {
+		s.driftPolicy = policy
+	}
 }
 
-func newStore(cfg []es.ClientOptionFunc, index string) (*Store, error) {
+// WithIndexSettings sets the settings and mappings the store's index is created with,
+// as a JSON string. Defaults to IndexSettings.
+func WithIndexSettings(settings string) Option {
+	return func(s *Store) {
+		s.settings = settings
+	}
+}
+
+// WithTenants sets the tenants whose files are isolated in their own indices.
+// Defaults to none, storing all files in a single index.
+func WithTenants(tenants ...string) Option {
+	return func(s *Store) {
+		s.tenants = tenants
+	}
+}
+
+// WithOwnerRouting sets how files are routed to shards by their ownerID.
+// Defaults to OwnerRoutingNone. Existing files are routed by their ownerID by reindexing
+// with OwnerRoutingWrite, after which OwnerRoutingFull can be set.
+func WithOwnerRouting(routing OwnerRouting) Option {
+	return func(s *Store) {
+		s.ownerRouting = routing
+	}
+}
+
+// WithMigrateOnStartup sets whether the store applies pending index migrations at startup.
+// Defaults to false, leaving migrations to be applied with Store.Migrate.
+func WithMigrateOnStartup(migrate bool) Option {
+	return func(s *Store) {
+		s.migrateOnStartup = migrate
+	}
+}
+
+// WithRollover enables rolling the write index over to a new index once it has maxDocs files,
+// or once its primary shards reach maxSize, i.e `50gb`. A zero maxDocs or an empty maxSize
+// disables the respective condition. Defaults to no rollover, writing to a single ever-growing index.
+func WithRollover(maxDocs int64, maxSize string) Option {
+	return func(s *Store) {
+		s.rolloverMaxDocs = maxDocs
+		s.rolloverMaxSize = maxSize
+	}
+}
+
+// WithRefresh sets the refresh policy of write requests that don't specify one.
+// Defaults to pb.Refresh_REFRESH_NONE, making the changes visible on the next periodic refresh.
+func WithRefresh(refresh pb.Refresh) Option {
+	return func(s *Store) {
+		s.refresh = refresh
+	}
+}
+
+// WithSnapshotRepository sets the shared filesystem snapshot repository the store's indices are
+// snapshotted to, registered at startup with location, which must be listed in the `path.repo`
+// setting of the elasticsearch nodes. Defaults to none, disabling snapshots.
+func WithSnapshotRepository(repository string, location string) Option {
+	return func(s *Store) {
+		s.snapshotRepository = repository
+		s.snapshotLocation = location
+	}
+}
+
+func newStore(cfg []es.ClientOptionFunc, index string, opts ...Option) (*Store, error) {
 	client, err := es.NewClient(cfg...)
 	if err != nil {
 		return nil, err
 	}
-	// Check if the index exists
-	exists, err := client.IndexExists(index).Do(context.Background())
+
+	store := &Store{
+		client:      client,
+		baseIndex:   index,
+		index:       index,
+		writeIndex:  writeAlias(index),
+		settings:    IndexSettings,
+		logger:      logrus.StandardLogger(),
+		driftPolicy: MappingDriftWarn,
+
+		ownerRouting: OwnerRoutingNone,
+		refresh:      pb.Refresh_REFRESH_NONE,
+		reindexes:    &reindexTargets{targets: make(map[string]reindexTarget)},
+	}
+
+	for _, opt := range opts {
+		opt(store)
+	}
+
+	switch store.driftPolicy {
+	case MappingDriftWarn, MappingDriftFail, MappingDriftApply:
+	default:
+		return nil, fmt.Errorf("invalid mapping drift policy: %s", store.driftPolicy)
+	}
+
+	if err := validateOwnerRouting(store.ownerRouting); err != nil {
+		return nil, err
+	}
+
+	switch store.refresh {
+	case pb.Refresh_REFRESH_NONE, pb.Refresh_REFRESH_WAIT_FOR, pb.Refresh_REFRESH_IMMEDIATE:
+	default:
+		return nil, fmt.Errorf("invalid default refresh policy: %s", store.refresh)
+	}
+
+	if err := validateIndexSettings(store.settings); err != nil {
+		return nil, err
+	}
+
+	if err := validateTenants(store.tenants); err != nil {
+		return nil, err
+	}
+
+	store.cluster, err = detectCluster(context.Background(), client)
 	if err != nil {
 		return nil, err
 	}
 
-	if !exists {
-		createIndex, err := client.CreateIndex(index).BodyString(IndexSettings).Do(context.Background())
-		if err != nil {
+	store.logger.WithField("cluster", store.cluster.String()).Info("connected to the search cluster")
+	if !store.cluster.supportsFlattened() {
+		store.logger.Infof("%s doesn't support the flattened field type, mapping %s as keyword sub-fields",
+			store.cluster, fieldMetadata)
+	}
+
+	if err := store.ensureSnapshotRepository(context.Background()); err != nil {
+		return nil, err
+	}
+
+	if len(store.tenants) > 0 {
+		if err := store.ensureTenantTemplate(context.Background()); err != nil {
 			return nil, err
 		}
+	}
+
+	stores, err := store.tenantStores(context.Background())
+	if err != nil {
+		return nil, err
+	}
 
-		if !createIndex.Acknowledged {
-			return nil, fmt.Errorf("failed creating index: %s", index)
+	for _, tenantStore := range stores {
+		if err := tenantStore.init(context.Background()); err != nil {
+			return nil, err
 		}
 	}
 
-	return &Store{client: client, index: index}, nil
+	return store, nil
+}
+
+// init makes sure the store's index and rollover template exist, migrates it if configured to,
+// and checks its mapping drift.
+func (s Store) init(ctx context.Context) error {
+	if err := s.ensureRolloverTemplate(ctx); err != nil {
+		return err
+	}
+
+	if err := s.ensureIndex(ctx); err != nil {
+		return err
+	}
+
+	if s.migrateOnStartup {
+		from, to, err := s.migrate(ctx)
+		if err != nil {
+			return err
+		}
+
+		s.logger.Infof("index %s migrated from schema version %d to %d", s.index, from, to)
+	}
+
+	return s.checkMappingDrift(ctx)
 }
 
 // HealthCheck checks the health of the service, returns true if healthy, or false otherwise.
+// The cluster health API is used rather than the cat API, since its response is the same
+// in elasticsearch and OpenSearch, which renamed the master columns of the cat API.
 func (s Store) HealthCheck(ctx context.Context) (bool, error) {
-	_, err := s.client.CatHealth().Do(ctx)
+	health, err := s.client.ClusterHealth().Do(ctx)
 	if err != nil {
 		return false, err
 	}
 
-	// Check if the index exists
-	exists, err := s.client.IndexExists(s.index).Do(context.Background())
+	// A red cluster has unassigned primary shards, so some of the files can't be read or written.
+	if health.Status == "red" {
+		return false, nil
+	}
+
+	stores, err := s.tenantStores(ctx)
 	if err != nil {
 		return false, err
 	}
 
-	return exists, nil
+	// Check if the indices exist
+	for _, store := range stores {
+		exists, err := s.client.IndexExists(store.index).Do(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		if !exists {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Get finds the file with the given id.
+// If successful returns the file and a nil error,
+// otherwise returns nil and non-nil error if any occurred.
+func (s Store) Get(ctx context.Context, id string) (*pb.File, error) {
+	s, err := s.tenantStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := s.locate(ctx, id, "")
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	res, err := s.client.Get().
+		Index(location.index).
+		Id(id).
+		Routing(location.routing).
+		Do(ctx)
+	if err != nil {
+		return nil, fileError(id, err)
+	}
+
+	if !res.Found {
+		return nil, fmt.Errorf("file %s: %w", id, service.ErrNotFound)
+	}
+
+	return decodeFile(res.Source)
+}
+
+// Exists finds which of the files with the given ids exist.
+// If successful returns the ids of the existing files and a nil error,
+// otherwise returns nil and non-nil error if any occurred.
+func (s Store) Exists(ctx context.Context, ids []string) ([]string, error) {
+	s, err := s.tenantStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files routed by their owner or in older rollover generations can't be found by id alone,
+	// so they are located in all indices, see locate.
+	if s.searchByID() {
+		return s.locateIDs(ctx, ids)
+	}
+
+	items := make([]*es.MultiGetItem, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, es.NewMultiGetItem().
+			Index(s.index).
+			Id(id).
+			FetchSource(es.NewFetchSourceContext(false)))
+	}
+
+	res, err := s.client.MultiGet().Add(items...).Do(ctx)
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	existing := make([]string, 0, len(res.Docs))
+	for _, doc := range res.Docs {
+		if doc.Error != nil {
+			return nil, fmt.Errorf("failed getting file %s: %s", doc.Id, doc.Error.Reason)
+		}
+
+		if doc.Found {
+			existing = append(existing, doc.Id)
+		}
+	}
+
+	return existing, nil
+}
+
+// locateIDs finds which of the files with the given ids exist with a realtime get like locate,
+// searching all shards only for the files that weren't found by it.
+func (s Store) locateIDs(ctx context.Context, ids []string) ([]string, error) {
+	locations, err := s.getLocations(ctx, ids, s.locateRoutings(ctx, ""))
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	missing := make([]string, 0, len(ids)-len(locations))
+	for _, id := range ids {
+		if _, ok := locations[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+
+	searched := make(map[string]bool, len(missing))
+	if len(missing) > 0 {
+		found, err := s.searchIDs(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range found {
+			searched[id] = true
+		}
+	}
+
+	existing := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := locations[id]; ok || searched[id] {
+			existing = append(existing, id)
+		}
+	}
+
+	return existing, nil
+}
+
+// searchIDs finds which of the files with the given ids exist by searching all shards.
+func (s Store) searchIDs(ctx context.Context, ids []string) ([]string, error) {
+	res, err := s.client.Search().
+		Index(s.index).
+		Query(es.NewIdsQuery().Ids(ids...)).
+		FetchSource(false).
+		Size(len(ids)).
+		Do(ctx)
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	existing := make([]string, 0, len(res.Hits.Hits))
+	for _, hit := range res.Hits.Hits {
+		existing = append(existing, hit.Id)
+	}
+
+	return existing, nil
 }
 
 // GetAll finds all files that matches the query and Index,
 // if successful returns a file slice, and a nil error,
 // otherwise returns nil and non-nil error if any occurred.
-func (s Store) GetAll(ctx context.Context, query es.Query) ([]string, error) {
+// A search scoped to an owner is routed to the owner's shard when owner routing is enabled.
+func (s Store) GetAll(ctx context.Context, query service.Query) ([]string, error) {
+	s, err := s.tenantStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	res, err := s.client.Search().
 		Index(s.index).
-		Query(query).
+		Query(searchQuery(query)).
+		Routing(s.searchRouting(query.OwnerID)).
+		Size(service.SearchSize).
 		Do(ctx)
 
 	if err != nil {
-		return nil, err
+		return nil, storeError(err)
 	}
 
 	ids := make([]string, 0, res.TotalHits())
@@ -76,51 +424,304 @@ func (s Store) GetAll(ctx context.Context, query es.Query) ([]string, error) {
 	return ids, nil
 }
 
-// Create creates a file.
+// Create creates a file, made visible to search according to refresh.
 // If successful returns the file id and a nil error,
 // otherwise returns empty string and non-nil error if any occurred.
-func (s Store) Create(ctx context.Context, file *pb.File) (string, error) {
+func (s Store) Create(ctx context.Context, file *pb.File, refresh pb.Refresh) (string, error) {
+	s, err := s.tenantStore(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	destination, err := s.reindexDestination(ctx)
+	if err != nil {
+		return "", storeError(err)
+	}
+
+	routing := s.createRouting(file.GetOwnerID())
 	res, err := s.client.Index().
-		Index(s.index).
+		Index(s.writeIndex).
 		Id(file.GetId()).
+		Routing(routing).
 		BodyJson(file).
+		Refresh(s.refreshParam(refresh)).
 		Do(ctx)
 
 	if err != nil {
-		return "", err
+		return "", storeError(err)
+	}
+
+	if err := s.mirrorWrite(ctx, destination, res.Index, res.Id, routing); err != nil {
+		return "", storeError(err)
 	}
 
 	return res.Id, nil
 }
 
-// Delete file from store by id.
+// Delete file from store by id, removed from search according to refresh.
 // If successful returns the file id and a nil error,
 // otherwise returns empty string and non-nil error if any occurred.
-func (s Store) Delete(ctx context.Context, id string) (string, error) {
+func (s Store) Delete(ctx context.Context, id string, refresh pb.Refresh) (string, error) {
+	s, err := s.tenantStore(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	location, err := s.locate(ctx, id, "")
+	if err != nil {
+		return "", storeError(err)
+	}
+
+	destination, err := s.reindexDestination(ctx)
+	if err != nil {
+		return "", storeError(err)
+	}
+
+	mirroredRouting, err := s.mirroredDeleteRouting(ctx, destination, location, id)
+	if err != nil {
+		return "", storeError(err)
+	}
+
 	res, err := s.client.Delete().
-		Index(s.index).
+		Index(location.index).
 		Id(id).
+		Routing(location.routing).
+		Refresh(s.refreshParam(refresh)).
 		Do(ctx)
 
 	if err != nil {
-		return "", err
+		return "", fileError(id, err)
+	}
+
+	if err := s.mirrorDelete(ctx, destination, res.Index, id, mirroredRouting, res.Version); err != nil {
+		return "", storeError(err)
 	}
 
 	return res.Id, nil
 }
 
-// Update file.
+// Update file, made visible to search according to refresh.
+// When owner routing is enabled and the file's owner changes, the file is moved to its new owner's shard,
+// see reroute.
 // If successful returns the file id and a nil error,
 // otherwise returns empty string and non-nil error if any occurred.
-func (s Store) Update(ctx context.Context, file *pb.File) (string, error) {
+func (s Store) Update(ctx context.Context, file *pb.File, refresh pb.Refresh) (string, error) {
+	s, err := s.tenantStore(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	location, err := s.locate(ctx, file.GetId(), file.GetOwnerID())
+	if err != nil {
+		return "", storeError(err)
+	}
+
+	destination, err := s.reindexDestination(ctx)
+	if err != nil {
+		return "", storeError(err)
+	}
+
+	if routing := s.createRouting(file.GetOwnerID()); routing != "" && location.routing != routing {
+		return s.reroute(ctx, location, file, refresh, destination)
+	}
+
 	res, err := s.client.Update().
-		Index(s.index).
+		Index(location.index).
 		Id(file.Id).
+		Routing(location.routing).
 		Doc(file).
+		Refresh(s.refreshParam(refresh)).
 		Do(ctx)
+	if err != nil {
+		return "", fileError(file.GetId(), err)
+	}
+
+	if err := s.mirrorWrite(ctx, destination, res.Index, res.Id, location.routing); err != nil {
+		return "", storeError(err)
+	}
+
+	return res.Id, nil
+}
+
+// Trash marks the file as trashed at deletedAt, made visible to search according to refresh.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s Store) Trash(ctx context.Context, id string, deletedAt int64, refresh pb.Refresh) (string, error) {
+	s, err := s.tenantStore(ctx)
 	if err != nil {
 		return "", err
 	}
 
+	return s.updateFields(ctx, id, map[string]interface{}{
+		fieldTrashed:   true,
+		fieldDeletedAt: deletedAt,
+	}, refresh)
+}
+
+// Restore clears the trashed state of the file, made visible to search according to refresh.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s Store) Restore(ctx context.Context, id string, refresh pb.Refresh) (string, error) {
+	s, err := s.tenantStore(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return s.updateFields(ctx, id, map[string]interface{}{
+		fieldTrashed:   false,
+		fieldDeletedAt: 0,
+	}, refresh)
+}
+
+// UpdatePermissions replaces the permissions granted on the file, made visible to search according to refresh.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s Store) UpdatePermissions(
+	ctx context.Context,
+	id string,
+	permissions []*pb.Permission,
+	refresh pb.Refresh,
+) (string, error) {
+	s, err := s.tenantStore(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Write an empty list rather than null when all permissions are revoked.
+	if permissions == nil {
+		permissions = []*pb.Permission{}
+	}
+
+	return s.updateFields(ctx, id, map[string]interface{}{
+		fieldPermissions: permissions,
+	}, refresh)
+}
+
+// PurgeTrashed permanently deletes all files that were trashed before trashedBefore,
+// of the tenant of the request in ctx, or of all tenants if ctx has none.
+// If successful returns the number of deleted files and a nil error,
+// otherwise returns 0 and non-nil error if any occurred.
+func (s Store) PurgeTrashed(ctx context.Context, trashedBefore int64) (int64, error) {
+	stores, err := s.tenantStores(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	for _, store := range stores {
+		deleted, err := store.purgeTrashed(ctx, trashedBefore)
+		purged += deleted
+		if err != nil {
+			return purged, err
+		}
+	}
+
+	return purged, nil
+}
+
+// purgeTrashed permanently deletes the files of the store's index that were trashed before trashedBefore.
+func (s Store) purgeTrashed(ctx context.Context, trashedBefore int64) (int64, error) {
+	query := es.NewBoolQuery().Filter(
+		es.NewTermQuery(fieldTrashed, true),
+		es.NewRangeQuery(fieldDeletedAt).Lte(trashedBefore),
+	)
+
+	res, err := s.client.DeleteByQuery(s.index).
+		Query(query).
+		ProceedOnVersionConflict().
+		Do(ctx)
+	if err != nil {
+		return 0, storeError(err)
+	}
+
+	return res.Deleted, nil
+}
+
+// updateFields partially updates the file with the given fields.
+// Used instead of Update when zero values must be written, since they are omitted from pb.File's json.
+func (s Store) updateFields(
+	ctx context.Context,
+	id string,
+	fields map[string]interface{},
+	refresh pb.Refresh,
+) (string, error) {
+	location, err := s.locate(ctx, id, "")
+	if err != nil {
+		return "", storeError(err)
+	}
+
+	destination, err := s.reindexDestination(ctx)
+	if err != nil {
+		return "", storeError(err)
+	}
+
+	res, err := s.client.Update().
+		Index(location.index).
+		Id(id).
+		Routing(location.routing).
+		Doc(fields).
+		Refresh(s.refreshParam(refresh)).
+		Do(ctx)
+	if err != nil {
+		return "", fileError(id, err)
+	}
+
+	if err := s.mirrorWrite(ctx, destination, res.Index, res.Id, location.routing); err != nil {
+		return "", storeError(err)
+	}
+
 	return res.Id, nil
 }
+
+// refreshParam returns the elasticsearch refresh parameter of a write request with refresh,
+// using the store's refresh policy if refresh is pb.Refresh_REFRESH_DEFAULT.
+func (s Store) refreshParam(refresh pb.Refresh) string {
+	if refresh == pb.Refresh_REFRESH_DEFAULT {
+		refresh = s.refresh
+	}
+
+	switch refresh {
+	case pb.Refresh_REFRESH_WAIT_FOR:
+		return "wait_for"
+	case pb.Refresh_REFRESH_IMMEDIATE:
+		return "true"
+	default:
+		return "false"
+	}
+}
+
+// decodeFile decodes a file document.
+// Files are indexed with encoding/json, which stores the fileOrId oneof under FileOrId as either
+// Parent or ParentObject, so the oneof is decoded separately from the rest of the file.
+func decodeFile(source []byte) (*pb.File, error) {
+	file := &pb.File{}
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err := unmarshaler.Unmarshal(bytes.NewReader(source), file); err != nil {
+		return nil, err
+	}
+
+	var stored struct {
+		FileOrID *struct {
+			Parent       *string
+			ParentObject json.RawMessage
+		} `json:"FileOrId"`
+	}
+	if err := json.Unmarshal(source, &stored); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case stored.FileOrID == nil:
+	case stored.FileOrID.Parent != nil:
+		file.FileOrId = &pb.File_Parent{Parent: *stored.FileOrID.Parent}
+	case len(stored.FileOrID.ParentObject) > 0:
+		parent, err := decodeFile(stored.FileOrID.ParentObject)
+		if err != nil {
+			return nil, err
+		}
+
+		file.FileOrId = &pb.File_ParentObject{ParentObject: parent}
+	}
+
+	return file, nil
+}
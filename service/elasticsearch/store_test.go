@@ -0,0 +1,147 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/meateam/search-service/proto"
+	"github.com/meateam/search-service/service"
+	"github.com/meateam/search-service/service/storetest"
+	es "github.com/olivere/elastic/v7"
+)
+
+// compatURLsEnv is the environment variable listing the comma separated urls of the clusters the store
+// conformance suite runs against, i.e the clusters of `make compat`. The suite is skipped if it's not set.
+const compatURLsEnv = "SS_COMPAT_ELASTICSEARCH_URLS"
+
+func TestStore(t *testing.T) {
+	forEachCluster(t, func(t *testing.T, clusterURL string) {
+		stores := 0
+		storetest.Run(t, func(t *testing.T) service.Store {
+			stores++
+			return newTestStore(t, clusterURL, fmt.Sprintf("storetest-%d-%d", time.Now().Unix(), stores))
+		})
+	})
+}
+
+func TestRolloverMigrateReindex(t *testing.T) {
+	forEachCluster(t, func(t *testing.T, clusterURL string) {
+		ctx := context.Background()
+		store := newTestStore(t, clusterURL, fmt.Sprintf("rollovertest-%d", time.Now().Unix()), WithRollover(1, ""))
+
+		ids := make([]string, 0, 3)
+		for i := 0; i < 3; i++ {
+			id, err := store.Create(ctx, &pb.File{
+				Id:      fmt.Sprintf("file-%d", i),
+				Name:    fmt.Sprintf("file %d", i),
+				OwnerID: "owner",
+			}, pb.Refresh_REFRESH_IMMEDIATE)
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			ids = append(ids, id)
+
+			// Roll over after each of the first two files, leaving three generations behind the write alias.
+			if i < 2 {
+				results, err := store.Rollover(ctx)
+				if err != nil {
+					t.Fatalf("Rollover() error = %v", err)
+				}
+
+				if len(results) != 1 || !results[0].RolledOver {
+					t.Fatalf("Rollover() = %+v, want a single rolled over index", results)
+				}
+			}
+		}
+
+		// Rewind the schema version of the latest generation so that every migration is applied again.
+		if err := store.setSchemaVersion(ctx, 0); err != nil {
+			t.Fatalf("setSchemaVersion() error = %v", err)
+		}
+
+		from, to, err := store.Migrate(ctx)
+		if err != nil {
+			t.Fatalf("Migrate() error = %v", err)
+		}
+
+		if from != 0 || to != latestSchemaVersion() {
+			t.Errorf("Migrate() = %d, %d, want %d, %d", from, to, 0, latestSchemaVersion())
+		}
+
+		if _, err := store.Reindex(ctx); err != nil {
+			t.Fatalf("Reindex() error = %v", err)
+		}
+
+		version, err := store.schemaVersion(ctx)
+		if err != nil {
+			t.Fatalf("schemaVersion() error = %v", err)
+		}
+
+		if version != latestSchemaVersion() {
+			t.Errorf("schemaVersion() = %d, want %d", version, latestSchemaVersion())
+		}
+
+		for _, id := range ids {
+			if _, err := store.Get(ctx, id); err != nil {
+				t.Errorf("Get(%q) error = %v", id, err)
+			}
+		}
+	})
+}
+
+// forEachCluster runs test as a subtest for each of the clusters listed in compatURLsEnv,
+// or skips it if it's not set.
+func forEachCluster(t *testing.T, test func(t *testing.T, clusterURL string)) {
+	urls := os.Getenv(compatURLsEnv)
+	if urls == "" {
+		t.Skipf("%s is not set", compatURLsEnv)
+	}
+
+	for _, clusterURL := range strings.Split(urls, ",") {
+		clusterURL := clusterURL
+		parsed, err := url.Parse(clusterURL)
+		if err != nil {
+			t.Fatalf("invalid %s url %q: %v", compatURLsEnv, clusterURL, err)
+		}
+
+		t.Run(parsed.Host, func(t *testing.T) {
+			test(t, clusterURL)
+		})
+	}
+}
+
+// newTestStore returns a store of index in the cluster at clusterURL, whose indices are deleted
+// when the test completes.
+func newTestStore(t *testing.T, clusterURL string, index string, opts ...Option) *Store {
+	cfg := []es.ClientOptionFunc{es.SetURL(clusterURL), es.SetSniff(false)}
+	store, err := newStore(cfg, index, opts...)
+	if err != nil {
+		t.Fatalf("newStore() error = %v", err)
+	}
+
+	t.Cleanup(func() {
+		deleteIndices(t, store)
+	})
+
+	return store
+}
+
+// deleteIndices deletes the indices behind the index alias of store.
+func deleteIndices(t *testing.T, store *Store) {
+	ctx := context.Background()
+	indices, err := store.resolveIndices(ctx, store.index)
+	if err != nil {
+		t.Errorf("failed resolving the indices of %s: %v", store.index, err)
+		return
+	}
+
+	if _, err := store.client.DeleteIndex(indices...).Do(ctx); err != nil {
+		t.Errorf("failed deleting the indices of %s: %v", store.index, err)
+	}
+}
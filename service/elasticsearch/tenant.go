@@ -0,0 +1,96 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/meateam/search-service/service"
+)
+
+// tenantPattern matches the valid tenant names, which must be valid in index names
+// and must not be confused with the index version and write alias suffixes.
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// tenantIndex returns the name of the index alias of tenant.
+func tenantIndex(index string, tenant string) string {
+	return index + "-" + tenant
+}
+
+// validateTenants validates the tenant names.
+func validateTenants(tenants []string) error {
+	for _, tenant := range tenants {
+		if !tenantPattern.MatchString(tenant) {
+			return fmt.Errorf("invalid tenant %q: must match %s", tenant, tenantPattern)
+		}
+	}
+
+	return nil
+}
+
+// forTenant returns a copy of the store operating on the index of tenant,
+// or on the configured index if tenant is empty.
+func (s Store) forTenant(tenant string) Store {
+	s.index = s.baseIndex
+	if tenant != "" {
+		s.index = tenantIndex(s.baseIndex, tenant)
+	}
+
+	s.writeIndex = writeAlias(s.index)
+
+	return s
+}
+
+// tenantStore returns a copy of the store operating on the index of the tenant of the request in ctx,
+// see service.RequestTenant.
+func (s Store) tenantStore(ctx context.Context) (Store, error) {
+	tenant, err := service.RequestTenant(ctx, s.tenants)
+	if err != nil {
+		return s, err
+	}
+
+	return s.forTenant(tenant), nil
+}
+
+// tenantStores returns copies of the store operating on the index of the tenant of the request in ctx,
+// or on the indices of all of the tenants if ctx has none.
+// Used by maintenance operations which apply to all of the tenants.
+func (s Store) tenantStores(ctx context.Context) ([]Store, error) {
+	if len(s.tenants) == 0 || service.TenantFromContext(ctx) != "" {
+		store, err := s.tenantStore(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return []Store{store}, nil
+	}
+
+	stores := make([]Store, 0, len(s.tenants))
+	for _, tenant := range s.tenants {
+		stores = append(stores, s.forTenant(tenant))
+	}
+
+	return stores, nil
+}
+
+// ensureTenantTemplate puts the index template shared by the tenants' indices,
+// which they are created from.
+func (s Store) ensureTenantTemplate(ctx context.Context) error {
+	body, err := s.indexBody()
+	if err != nil {
+		return err
+	}
+
+	body["index_patterns"] = []string{tenantIndex(s.baseIndex, "*")}
+
+	res, err := s.client.IndexPutTemplate(tenantIndex(s.baseIndex, "tenants")).BodyJson(body).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !res.Acknowledged {
+		return fmt.Errorf("failed putting index template for the tenants of %s", s.baseIndex)
+	}
+
+	return nil
+}
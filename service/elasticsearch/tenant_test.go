@@ -0,0 +1,100 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/meateam/search-service/service"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestValidateTenants(t *testing.T) {
+	tests := []struct {
+		name    string
+		tenants []string
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "valid", tenants: []string{"acme", "tenant-1", "42"}},
+		{name: "uppercase", tenants: []string{"Acme"}, wantErr: true},
+		{name: "leading dash", tenants: []string{"-acme"}, wantErr: true},
+		{name: "underscore", tenants: []string{"acme_v1"}, wantErr: true},
+		{name: "empty", tenants: []string{"acme", ""}, wantErr: true},
+		{name: "path", tenants: []string{"acme/other"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateTenants(tt.tenants); (err != nil) != tt.wantErr {
+				t.Errorf("validateTenants(%q) error = %v, wantErr %v", tt.tenants, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTenantStore(t *testing.T) {
+	tests := []struct {
+		name      string
+		tenants   []string
+		tenant    string
+		wantIndex string
+		wantErr   bool
+	}{
+		{name: "tenants disabled", wantIndex: "files"},
+		{name: "tenant while disabled", tenant: "acme", wantErr: true},
+		{name: "configured tenant", tenants: []string{"acme", "globex"}, tenant: "globex", wantIndex: "files-globex"},
+		{name: "missing tenant", tenants: []string{"acme"}, wantErr: true},
+		{name: "unknown tenant", tenants: []string{"acme"}, tenant: "globex", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.tenant != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(service.TenantMetadataKey, tt.tenant))
+			}
+
+			s := Store{baseIndex: "files", index: "files", writeIndex: writeAlias("files"), tenants: tt.tenants}
+			got, err := s.tenantStore(ctx)
+			if tt.wantErr {
+				var validationErr *service.ValidationError
+				if !errors.As(err, &validationErr) || validationErr.Violations[0].Field != service.TenantMetadataKey {
+					t.Errorf("tenantStore() error = %v, want an invalid %s", err, service.TenantMetadataKey)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("tenantStore() error = %v", err)
+			}
+
+			if got.index != tt.wantIndex || got.writeIndex != writeAlias(tt.wantIndex) {
+				t.Errorf("tenantStore() index = %s, %s, want %s, %s",
+					got.index, got.writeIndex, tt.wantIndex, writeAlias(tt.wantIndex))
+			}
+		})
+	}
+}
+
+func TestTenantStores(t *testing.T) {
+	s := Store{baseIndex: "files", index: "files", writeIndex: writeAlias("files")}
+	s.tenants = []string{"acme", "globex"}
+	stores, err := s.tenantStores(context.Background())
+	if err != nil {
+		t.Fatalf("tenantStores() error = %v", err)
+	}
+
+	indices := make([]string, 0, len(stores))
+	for _, store := range stores {
+		indices = append(indices, store.index)
+	}
+
+	if want := []string{"files-acme", "files-globex"}; !reflect.DeepEqual(indices, want) {
+		t.Errorf("tenantStores() indices = %v, want %v", indices, want)
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrConflict is returned by a Store when a write conflicts with a concurrent change of the file.
+	ErrConflict = errors.New("conflicting concurrent change")
+
+	// ErrUnavailable is returned by a Store when its backend can't currently serve requests.
+	ErrUnavailable = errors.New("backend unavailable")
+)
+
+// FieldViolation is a violation of the rules of a field of a request.
+type FieldViolation struct {
+	// Field is the path of the field in the request, i.e `permissions[1].userID`.
+	Field string
+
+	// Description describes why the field is invalid.
+	Description string
+}
+
+// ValidationError is returned when a request is invalid, with the violations of its fields.
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+// Error returns the descriptions of the violations.
+func (e *ValidationError) Error() string {
+	descriptions := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		descriptions = append(descriptions, violation.Description)
+	}
+
+	return "invalid request: " + strings.Join(descriptions, ", ")
+}
+
+// InvalidField returns a ValidationError of the single field violation described by format and args.
+func InvalidField(field string, format string, args ...interface{}) error {
+	return &ValidationError{Violations: []FieldViolation{{Field: field, Description: fmt.Sprintf(format, args...)}}}
+}
+
+// Status returns err as a grpc status error with the code matching its cause, so clients can react to it:
+// ValidationError is InvalidArgument with BadRequest details of its field violations,
+// ErrNotFound is NotFound, ErrConflict is Aborted, ErrUnsupported is Unimplemented,
+// ErrUnavailable is Unavailable, and context errors are DeadlineExceeded or Canceled.
+// Other errors, and errors which already are status errors, are returned as is.
+// Returns nil if err is nil.
+func Status(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return validationStatus(validationErr)
+	case errors.Is(err, ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, ErrUnsupported):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, ErrUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		return err
+	}
+}
+
+// validationStatus returns the InvalidArgument status of err, with its field violations as BadRequest details.
+func validationStatus(err *ValidationError) error {
+	badRequest := &errdetails.BadRequest{}
+	for _, violation := range err.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       violation.Field,
+			Description: violation.Description,
+		})
+	}
+
+	st, detailsErr := status.New(codes.InvalidArgument, err.Error()).WithDetails(badRequest)
+	if detailsErr != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return st.Err()
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/meateam/search-service/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatus(t *testing.T) {
+	raw := errors.New("raw")
+
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    codes.Code
+		wantDetails []proto.Message
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "not found",
+			err:      fmt.Errorf("file file: %w", ErrNotFound),
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "missing snapshot",
+			err:      fmt.Errorf("snapshot snapshot: %w", ErrNotFound),
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "missing snapshot name",
+			err:      validateRestoreSnapshot(&pb.RestoreSnapshotRequest{}),
+			wantCode: codes.InvalidArgument,
+			wantDetails: []proto.Message{&errdetails.BadRequest{
+				FieldViolations: []*errdetails.BadRequest_FieldViolation{
+					{Field: "name", Description: "name is required"},
+				},
+			}},
+		},
+		{
+			name:     "invalid field",
+			err:      InvalidField(TenantMetadataKey, "unknown tenant %s", "tenant"),
+			wantCode: codes.InvalidArgument,
+			wantDetails: []proto.Message{&errdetails.BadRequest{
+				FieldViolations: []*errdetails.BadRequest_FieldViolation{
+					{Field: TenantMetadataKey, Description: "unknown tenant tenant"},
+				},
+			}},
+		},
+		{
+			name:     "conflict",
+			err:      fmt.Errorf("%w: version conflict", ErrConflict),
+			wantCode: codes.Aborted,
+		},
+		{
+			name:     "unsupported",
+			err:      fmt.Errorf("snapshots: %w", ErrUnsupported),
+			wantCode: codes.Unimplemented,
+		},
+		{
+			name:     "unavailable",
+			err:      fmt.Errorf("%w: no available connection", ErrUnavailable),
+			wantCode: codes.Unavailable,
+		},
+		{
+			name:     "deadline exceeded",
+			err:      fmt.Errorf("search: %w", context.DeadlineExceeded),
+			wantCode: codes.DeadlineExceeded,
+		},
+		{
+			name:     "canceled",
+			err:      fmt.Errorf("search: %w", context.Canceled),
+			wantCode: codes.Canceled,
+		},
+		{
+			name:     "status",
+			err:      status.Error(codes.PermissionDenied, "denied"),
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "raw",
+			err:      raw,
+			wantCode: codes.Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := Status(tt.err)
+			st := status.Convert(err)
+			if st.Code() != tt.wantCode {
+				t.Errorf("Status() code = %s, want %s", st.Code(), tt.wantCode)
+			}
+
+			details := st.Details()
+			if len(details) != len(tt.wantDetails) {
+				t.Fatalf("Status() details = %v, want %v", details, tt.wantDetails)
+			}
+
+			for i, detail := range details {
+				message, ok := detail.(proto.Message)
+				if !ok || !proto.Equal(message, tt.wantDetails[i]) {
+					t.Errorf("Status() details[%d] = %v, want %v", i, detail, tt.wantDetails[i])
+				}
+			}
+		})
+	}
+
+	t.Run("raw passed through", func(t *testing.T) {
+		if err := Status(raw); err != raw {
+			t.Errorf("Status() = %v, want %v", err, raw)
+		}
+	})
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/meateam/search-service/proto"
+)
+
+const (
+	// filterTagPrefix is the prefix of a search filter matching files by tag, i.e `tag:finance`.
+	filterTagPrefix = "tag:"
+
+	// filterMetadataPrefix is the prefix of a search filter matching files by metadata value,
+	// i.e `metadata.project=x`.
+	filterMetadataPrefix = "metadata."
+)
+
+// FileController is the backend-neutral business logic of the files operations of Controller,
+// implemented using a Store. Backends embed it in their Controller and add the admin operations.
+// The requests are expected to be validated by the Service before reaching the controller.
+type FileController struct {
+	store Store
+}
+
+// NewFileController returns a new FileController using store.
+func NewFileController(store Store) FileController {
+	return FileController{store: store}
+}
+
+// HealthCheck runs store's healthcheck and returns true if healthy, otherwise returns false
+// and any error if occurred.
+func (c FileController) HealthCheck(ctx context.Context) (bool, error) {
+	return c.store.HealthCheck(ctx)
+}
+
+// CreateFile creates a file in store and returns its unique ID.
+func (c FileController) CreateFile(ctx context.Context, req *pb.File) (*pb.CreateFileResponse, error) {
+	refresh := req.GetRefresh()
+	file := formatFile(req)
+	id, err := c.store.Create(ctx, file, refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateFileResponse{Id: id}, nil
+}
+
+// Search retrieves a list of the file ids that match the search term, and any error if occurred.
+func (c FileController) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	query := Query{
+		Term:     req.GetTerm(),
+		UserID:   req.GetUserID(),
+		GroupIDs: req.GetGroupIDs(),
+		OwnerID:  req.GetOwnerID(),
+	}
+
+	// Trashed files are excluded from the results unless explicitly requested.
+	switch {
+	case req.GetOnlyTrashed():
+		query.Trashed = TrashedOnly
+	case req.GetIncludeTrashed():
+		query.Trashed = TrashedInclude
+	}
+
+	for i, filter := range req.GetFilters() {
+		if err := parseFilter(filter, &query); err != nil {
+			return nil, InvalidField(fmt.Sprintf("filters[%d]", i), "%v", err)
+		}
+	}
+
+	ids, err := c.store.GetAll(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SearchResponse{Ids: ids}, nil
+}
+
+// GetFile retrieves the indexed file with the given id, and any error if occurred.
+func (c FileController) GetFile(ctx context.Context, req *pb.GetFileRequest) (*pb.File, error) {
+	return c.store.Get(WithOwnerID(ctx, req.GetOwnerID()), req.GetId())
+}
+
+// Exists retrieves the ids of the given files that are indexed, and any error if occurred.
+func (c FileController) Exists(ctx context.Context, req *pb.ExistsRequest) (*pb.ExistsResponse, error) {
+	if len(req.GetIds()) == 0 {
+		return &pb.ExistsResponse{Ids: []string{}}, nil
+	}
+
+	ids, err := c.store.Exists(WithOwnerID(ctx, req.GetOwnerID()), req.GetIds())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ExistsResponse{Ids: ids}, nil
+}
+
+// Delete retrieves a file id and id the match file by fild id from store, and any error if occurred.
+func (c FileController) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
+	res, err := c.store.Delete(WithOwnerID(ctx, req.GetOwnerID()), req.GetId(), req.GetRefresh())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DeleteResponse{Id: res}, nil
+}
+
+// Update retrieves a file and update the match file id, and any error if occurred.
+func (c FileController) Update(ctx context.Context, req *pb.File) (*pb.UpdateResponse, error) {
+	refresh := req.GetRefresh()
+	file := formatFile(req)
+
+	res, err := c.store.Update(ctx, file, refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.UpdateResponse{Id: res}, nil
+}
+
+// Trash marks the file with the given id as trashed so it's excluded from searches by default,
+// and any error if occurred.
+func (c FileController) Trash(ctx context.Context, req *pb.TrashRequest) (*pb.TrashResponse, error) {
+	ctx = WithOwnerID(ctx, req.GetOwnerID())
+	res, err := c.store.Trash(ctx, req.GetId(), unixMillis(time.Now()), req.GetRefresh())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.TrashResponse{Id: res}, nil
+}
+
+// Restore restores the trashed file with the given id, and any error if occurred.
+func (c FileController) Restore(ctx context.Context, req *pb.RestoreRequest) (*pb.RestoreResponse, error) {
+	res, err := c.store.Restore(WithOwnerID(ctx, req.GetOwnerID()), req.GetId(), req.GetRefresh())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RestoreResponse{Id: res}, nil
+}
+
+// UpdatePermissions replaces the permissions granted on the file with the given id,
+// and any error if occurred.
+func (c FileController) UpdatePermissions(
+	ctx context.Context,
+	req *pb.UpdatePermissionsRequest,
+) (*pb.UpdatePermissionsResponse, error) {
+	ctx = WithOwnerID(ctx, req.GetOwnerID())
+	res, err := c.store.UpdatePermissions(ctx, req.GetId(), req.GetPermissions(), req.GetRefresh())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.UpdatePermissionsResponse{Id: res}, nil
+}
+
+// PurgeTrashed permanently deletes the files that were trashed more than retention ago,
+// returns the number of deleted files and any error if occurred.
+func (c FileController) PurgeTrashed(ctx context.Context, retention time.Duration) (int64, error) {
+	trashedBefore := unixMillis(time.Now().Add(-retention))
+
+	return c.store.PurgeTrashed(ctx, trashedBefore)
+}
+
+// parseFilter parses a search filter, either `tag:<tag>` or `metadata.<key>=<value>`,
+// into query, returns an error if the filter is invalid.
+func parseFilter(filter string, query *Query) error {
+	switch {
+	case strings.HasPrefix(filter, filterTagPrefix):
+		tag := strings.TrimPrefix(filter, filterTagPrefix)
+		if tag == "" {
+			return fmt.Errorf("invalid filter %q: tag is required", filter)
+		}
+
+		query.Tags = append(query.Tags, tag)
+	case strings.HasPrefix(filter, filterMetadataPrefix):
+		keyValue := strings.SplitN(strings.TrimPrefix(filter, filterMetadataPrefix), "=", 2)
+		if len(keyValue) != 2 || keyValue[0] == "" {
+			return fmt.Errorf("invalid filter %q: expected %s<key>=<value>", filter, filterMetadataPrefix)
+		}
+
+		if query.Metadata == nil {
+			query.Metadata = make(map[string]string)
+		}
+
+		query.Metadata[keyValue[0]] = keyValue[1]
+	default:
+		return fmt.Errorf("invalid filter %q: expected %s<tag> or %s<key>=<value>",
+			filter, filterTagPrefix, filterMetadataPrefix)
+	}
+
+	return nil
+}
+
+// unixMillis returns t as the number of milliseconds since the unix epoch,
+// which is the resolution of the files' timestamps.
+func unixMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// formatFile formats a given file so there won't be elastic indexing errors.
+func formatFile(file *pb.File) *pb.File {
+	fileName := formatFileName(file.GetName())
+	file.Name = fileName
+
+	// The refresh policy is a request option and isn't stored with the file.
+	file.Refresh = pb.Refresh_REFRESH_DEFAULT
+	return file
+}
+
+// formatFileName formats a given fileName to prevent elasticsearch startoffset errors.
+func formatFileName(name string) string {
+	fileName := strings.ReplaceAll(name, "_", " ")
+	return fileName
+}
@@ -0,0 +1,23 @@
+package memory
+
+import (
+	"github.com/meateam/search-service/service"
+)
+
+// backendName is the name of the memory backend.
+const backendName = "memory"
+
+// Controller is the search service business logic implementation using the in-memory store.
+// The memory backend has no indices, so it doesn't support the admin operations.
+type Controller struct {
+	service.FileController
+	service.UnsupportedAdmin
+}
+
+// NewController returns a new controller with an empty in-memory store configured by opts.
+func NewController(opts ...Option) *Controller {
+	return &Controller{
+		FileController:   service.NewFileController(NewStore(opts...)),
+		UnsupportedAdmin: service.UnsupportedAdmin{Backend: backendName},
+	}
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	pb "github.com/meateam/search-service/proto"
+	"github.com/meateam/search-service/service"
+)
+
+// result is a file matching a search, and its relevance score.
+type result struct {
+	file  *pb.File
+	score int
+}
+
+// search returns the ids of the most relevant files matching query, at most service.SearchSize of them.
+func search(files map[string]*pb.File, query service.Query) []string {
+	terms := tokenize(query.Term)
+	results := make([]result, 0)
+	for _, file := range files {
+		if !matches(file, query) {
+			continue
+		}
+
+		if score := score(file, terms); score > 0 {
+			results = append(results, result{file: file, score: score})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return less(results[i], results[j])
+	})
+
+	ids := make([]string, 0, service.SearchSize)
+	for i := 0; i < len(results) && len(ids) < service.SearchSize; i++ {
+		ids = append(ids, results[i].file.GetId())
+	}
+
+	return ids
+}
+
+// matches returns true if file matches the filters of query.
+func matches(file *pb.File, query service.Query) bool {
+	switch query.Trashed {
+	case service.TrashedExclude:
+		if file.GetTrashed() {
+			return false
+		}
+	case service.TrashedOnly:
+		if !file.GetTrashed() {
+			return false
+		}
+	}
+
+	if query.UserID != "" && !hasAccess(file, query.UserID, query.GroupIDs) {
+		return false
+	}
+
+	if query.OwnerID != "" && file.GetOwnerID() != query.OwnerID {
+		return false
+	}
+
+	for _, tag := range query.Tags {
+		if !contains(file.GetTags(), tag) {
+			return false
+		}
+	}
+
+	for key, value := range query.Metadata {
+		if fileValue, ok := file.GetMetadata()[key]; !ok || fileValue != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasAccess returns true if file is owned by userID, or shared with userID or with any of groupIDs.
+func hasAccess(file *pb.File, userID string, groupIDs []string) bool {
+	if file.GetOwnerID() == userID {
+		return true
+	}
+
+	for _, permission := range file.GetPermissions() {
+		if permission.GetUserID() == userID ||
+			(permission.GetGroupID() != "" && contains(groupIDs, permission.GetGroupID())) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// score returns the number of terms that prefix any of the words of the file's text fields.
+func score(file *pb.File, terms []string) int {
+	words := tokenize(strings.Join([]string{file.GetName(), file.GetDescription(), file.GetType()}, " "))
+	score := 0
+	for _, term := range terms {
+		for _, word := range words {
+			if strings.HasPrefix(word, term) {
+				score++
+				break
+			}
+		}
+	}
+
+	return score
+}
+
+// less returns true if a is ordered before b.
+// Results are ordered by relevance, higher scores first, with ties broken by id.
+func less(a result, b result) bool {
+	if a.score != b.score {
+		return a.score > b.score
+	}
+
+	return a.file.GetId() < b.file.GetId()
+}
+
+// tokenize splits text into its lowercase words.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// contains returns true if values contains value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
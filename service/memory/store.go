@@ -0,0 +1,270 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/meateam/search-service/proto"
+	"github.com/meateam/search-service/service"
+)
+
+// Store is an in-memory implementation of service.Store, for tests and local development.
+// Files are kept separately for each of the configured tenants, and are lost when the process exits.
+// Writes are visible to search right away, regardless of their refresh policy.
+type Store struct {
+	mu      sync.RWMutex
+	tenants map[string]map[string]*pb.File
+
+	// allowedTenants are the configured tenants, see WithTenants.
+	allowedTenants []string
+}
+
+// Option configures optional behavior of the Store.
+type Option func(*Store)
+
+// WithTenants sets the tenants whose files are kept separately, selected by the request's tenant metadata,
+// which must be one of them. Defaults to no tenants, rejecting requests that have a tenant.
+func WithTenants(tenants ...string) Option {
+	return func(s *Store) {
+		s.allowedTenants = tenants
+	}
+}
+
+// NewStore returns a new empty Store.
+func NewStore(opts ...Option) *Store {
+	store := &Store{tenants: make(map[string]map[string]*pb.File)}
+	for _, opt := range opts {
+		opt(store)
+	}
+
+	return store
+}
+
+// HealthCheck checks the health of the store, which is always healthy.
+func (s *Store) HealthCheck(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+// Get finds the file with the given id.
+// If successful returns the file and a nil error,
+// otherwise returns nil and non-nil error if any occurred.
+func (s *Store) Get(ctx context.Context, id string) (*pb.File, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files, err := s.files(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	file, ok := files[id]
+	if !ok {
+		return nil, fmt.Errorf("file %s: %w", id, service.ErrNotFound)
+	}
+
+	return proto.Clone(file).(*pb.File), nil
+}
+
+// Exists finds which of the files with the given ids exist.
+// If successful returns the ids of the existing files and a nil error,
+// otherwise returns nil and non-nil error if any occurred.
+func (s *Store) Exists(ctx context.Context, ids []string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files, err := s.files(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := files[id]; ok {
+			existing = append(existing, id)
+		}
+	}
+
+	return existing, nil
+}
+
+// GetAll finds the files that match query,
+// if successful returns the ids of the files, and a nil error,
+// otherwise returns nil and non-nil error if any occurred.
+func (s *Store) GetAll(ctx context.Context, query service.Query) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files, err := s.files(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return search(files, query), nil
+}
+
+// Create creates a file, generating its id if it has none.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) Create(ctx context.Context, file *pb.File, refresh pb.Refresh) (string, error) {
+	tenant, err := service.RequestTenant(ctx, s.allowedTenants)
+	if err != nil {
+		return "", err
+	}
+
+	file = proto.Clone(file).(*pb.File)
+	if file.GetId() == "" {
+		id, err := service.NewFileID()
+		if err != nil {
+			return "", err
+		}
+
+		file.Id = id
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, ok := s.tenants[tenant]
+	if !ok {
+		files = make(map[string]*pb.File)
+		s.tenants[tenant] = files
+	}
+
+	files[file.GetId()] = file
+
+	return file.GetId(), nil
+}
+
+// Delete file from store by id.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) Delete(ctx context.Context, id string, refresh pb.Refresh) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := s.files(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := files[id]; !ok {
+		return "", fmt.Errorf("file %s: %w", id, service.ErrNotFound)
+	}
+
+	delete(files, id)
+
+	return id, nil
+}
+
+// Update partially updates the file with the fields set in file.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) Update(ctx context.Context, file *pb.File, refresh pb.Refresh) (string, error) {
+	return s.update(ctx, file.GetId(), func(stored *pb.File) {
+		service.MergeFile(stored, file)
+	})
+}
+
+// Trash marks the file as trashed at deletedAt.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) Trash(ctx context.Context, id string, deletedAt int64, refresh pb.Refresh) (string, error) {
+	return s.update(ctx, id, func(stored *pb.File) {
+		stored.Trashed = true
+		stored.DeletedAt = deletedAt
+	})
+}
+
+// Restore clears the trashed state of the file.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) Restore(ctx context.Context, id string, refresh pb.Refresh) (string, error) {
+	return s.update(ctx, id, func(stored *pb.File) {
+		stored.Trashed = false
+		stored.DeletedAt = 0
+	})
+}
+
+// UpdatePermissions replaces the permissions granted on the file.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) UpdatePermissions(
+	ctx context.Context,
+	id string,
+	permissions []*pb.Permission,
+	refresh pb.Refresh,
+) (string, error) {
+	return s.update(ctx, id, func(stored *pb.File) {
+		stored.Permissions = make([]*pb.Permission, 0, len(permissions))
+		for _, permission := range permissions {
+			stored.Permissions = append(stored.Permissions, proto.Clone(permission).(*pb.Permission))
+		}
+	})
+}
+
+// PurgeTrashed permanently deletes all files that were trashed before trashedBefore,
+// of the tenant of the request in ctx, or of all tenants if ctx has none.
+// If successful returns the number of deleted files and a nil error,
+// otherwise returns 0 and non-nil error if any occurred.
+func (s *Store) PurgeTrashed(ctx context.Context, trashedBefore int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenants := make([]map[string]*pb.File, 0, len(s.tenants))
+	if service.TenantFromContext(ctx) == "" {
+		for _, files := range s.tenants {
+			tenants = append(tenants, files)
+		}
+	} else {
+		files, err := s.files(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		tenants = append(tenants, files)
+	}
+
+	var purged int64
+	for _, files := range tenants {
+		for id, file := range files {
+			if file.GetTrashed() && file.GetDeletedAt() <= trashedBefore {
+				delete(files, id)
+				purged++
+			}
+		}
+	}
+
+	return purged, nil
+}
+
+// update applies apply to the stored file with the given id.
+func (s *Store) update(ctx context.Context, id string, apply func(stored *pb.File)) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := s.files(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	stored, ok := files[id]
+	if !ok {
+		return "", fmt.Errorf("file %s: %w", id, service.ErrNotFound)
+	}
+
+	apply(stored)
+
+	return id, nil
+}
+
+// files returns the files of the tenant of the request in ctx, see service.RequestTenant, s.mu must be held.
+func (s *Store) files(ctx context.Context) (map[string]*pb.File, error) {
+	tenant, err := service.RequestTenant(ctx, s.allowedTenants)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tenants[tenant], nil
+}
@@ -0,0 +1,20 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/meateam/search-service/service"
+	"github.com/meateam/search-service/service/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) service.Store {
+		return NewStore()
+	})
+}
+
+func TestStoreTenants(t *testing.T) {
+	storetest.RunTenants(t, func(t *testing.T, tenants ...string) service.Store {
+		return NewStore(WithTenants(tenants...))
+	})
+}
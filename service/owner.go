@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+)
+
+// ownerIDKey is the context key of the owner hint of a request.
+type ownerIDKey struct{}
+
+// WithOwnerID returns a copy of ctx carrying ownerID as a hint of the owner of the files a request operates on,
+// which lets stores that route files by their owner find them in the owner's shard.
+// Returns ctx as is if ownerID is empty.
+func WithOwnerID(ctx context.Context, ownerID string) context.Context {
+	if ownerID == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, ownerIDKey{}, ownerID)
+}
+
+// OwnerIDFromContext returns the owner hint of the request in ctx,
+// or an empty string if the request has none.
+func OwnerIDFromContext(ctx context.Context) string {
+	ownerID, _ := ctx.Value(ownerIDKey{}).(string)
+
+	return ownerID
+}
@@ -2,23 +2,25 @@ package service
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	pb "github.com/meateam/search-service/proto"
 	"github.com/sirupsen/logrus"
 )
 
-// Service is a structure used for handling Search Service grpc requests.
+// Service is a structure used for handling Search Service and its Admin Service grpc requests.
 type Service struct {
 	logger     *logrus.Logger
 	controller Controller
+
+	// pendingWrites counts the write requests being handled, see PendingWrites.
+	pendingWrites *int64
 }
 
-// HealthCheck checks the health of the service, returns true if healthy, or false otherwise.
-func (s Service) HealthCheck() bool {
-	timeoutCtx, cancel := context.WithTimeout(context.TODO(), time.Minute)
-	defer cancel()
-	healthy, err := s.controller.HealthCheck(timeoutCtx)
+// HealthCheck checks the health of the service within ctx, returns true if healthy, or false otherwise.
+func (s Service) HealthCheck(ctx context.Context) bool {
+	healthy, err := s.controller.HealthCheck(ctx)
 	if err != nil {
 		s.logger.Errorf("%v", err)
 		return false
@@ -27,27 +29,186 @@ func (s Service) HealthCheck() bool {
 	return healthy
 }
 
+// CheckMapping checks within ctx that the store's mappings match the expected ones,
+// returns true if they match, or false otherwise.
+func (s Service) CheckMapping(ctx context.Context) bool {
+	drift, err := s.controller.MappingDrift(ctx)
+	if err != nil {
+		s.logger.Errorf("%v", err)
+		return false
+	}
+
+	return len(drift) == 0
+}
+
+// PurgeTrashed permanently deletes the files that were trashed more than retention ago within ctx,
+// returns the number of deleted files and any error if occurred.
+func (s Service) PurgeTrashed(ctx context.Context, retention time.Duration) (int64, error) {
+	return s.controller.PurgeTrashed(ctx, retention)
+}
+
+// Rollover rolls the write indices that are too large over to new indices within ctx,
+// returns the indices rolled over to and any error if occurred.
+func (s Service) Rollover(ctx context.Context) ([]string, error) {
+	return s.controller.Rollover(ctx)
+}
+
+// PendingWrites returns the number of write requests currently being handled, which grows when
+// the controller can't keep up with the writes.
+func (s Service) PendingWrites() int64 {
+	return atomic.LoadInt64(s.pendingWrites)
+}
+
+// startWrite counts a write request as pending until the returned func is called.
+func (s Service) startWrite() func() {
+	atomic.AddInt64(s.pendingWrites, 1)
+	return func() {
+		atomic.AddInt64(s.pendingWrites, -1)
+	}
+}
+
 // NewService creates a Service and returns it.
+// The handlers validate the requests before passing them to the controller,
+// and return the controller's errors as grpc status errors, see Status.
 func NewService(controller Controller, logger *logrus.Logger) Service {
-	return Service{controller: controller, logger: logger}
+	return Service{controller: controller, logger: logger, pendingWrites: new(int64)}
 }
 
 // CreateFile is the request handler for creating a file.
 func (s Service) CreateFile(ctx context.Context, req *pb.File) (*pb.CreateFileResponse, error) {
-	return s.controller.CreateFile(ctx, req)
+	if err := validateCreateFile(req); err != nil {
+		return nil, Status(err)
+	}
+
+	defer s.startWrite()()
+	res, err := s.controller.CreateFile(ctx, req)
+	return res, Status(err)
 }
 
 // Search is the request handler for searching a file.
 func (s Service) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
-	return s.controller.Search(ctx, req)
+	if err := validateSearch(req); err != nil {
+		return nil, Status(err)
+	}
+
+	res, err := s.controller.Search(ctx, req)
+	return res, Status(err)
+}
+
+// GetFile is the request handler for retrieving an indexed file.
+func (s Service) GetFile(ctx context.Context, req *pb.GetFileRequest) (*pb.File, error) {
+	if err := validateID(req.GetId()); err != nil {
+		return nil, Status(err)
+	}
+
+	res, err := s.controller.GetFile(ctx, req)
+	return res, Status(err)
+}
+
+// Exists is the request handler for checking which of the given files are indexed.
+func (s Service) Exists(ctx context.Context, req *pb.ExistsRequest) (*pb.ExistsResponse, error) {
+	if err := validateExists(req); err != nil {
+		return nil, Status(err)
+	}
+
+	res, err := s.controller.Exists(ctx, req)
+	return res, Status(err)
 }
 
 // Delete is the request handler for deleting a file.
 func (s Service) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
-	return s.controller.Delete(ctx, req)
+	if err := validateWrite(req.GetId(), req.GetRefresh()); err != nil {
+		return nil, Status(err)
+	}
+
+	defer s.startWrite()()
+	res, err := s.controller.Delete(ctx, req)
+	return res, Status(err)
 }
 
 // Update is the request handler for updating a file.
 func (s Service) Update(ctx context.Context, req *pb.File) (*pb.UpdateResponse, error) {
-	return s.controller.Update(ctx, req)
+	if err := validateUpdate(req); err != nil {
+		return nil, Status(err)
+	}
+
+	defer s.startWrite()()
+	res, err := s.controller.Update(ctx, req)
+	return res, Status(err)
+}
+
+// Trash is the request handler for moving a file to the trash.
+func (s Service) Trash(ctx context.Context, req *pb.TrashRequest) (*pb.TrashResponse, error) {
+	if err := validateWrite(req.GetId(), req.GetRefresh()); err != nil {
+		return nil, Status(err)
+	}
+
+	defer s.startWrite()()
+	res, err := s.controller.Trash(ctx, req)
+	return res, Status(err)
+}
+
+// Restore is the request handler for restoring a file from the trash.
+func (s Service) Restore(ctx context.Context, req *pb.RestoreRequest) (*pb.RestoreResponse, error) {
+	if err := validateWrite(req.GetId(), req.GetRefresh()); err != nil {
+		return nil, Status(err)
+	}
+
+	defer s.startWrite()()
+	res, err := s.controller.Restore(ctx, req)
+	return res, Status(err)
+}
+
+// UpdatePermissions is the request handler for replacing the permissions of a file.
+func (s Service) UpdatePermissions(
+	ctx context.Context,
+	req *pb.UpdatePermissionsRequest,
+) (*pb.UpdatePermissionsResponse, error) {
+	if err := validateUpdatePermissions(req); err != nil {
+		return nil, Status(err)
+	}
+
+	defer s.startWrite()()
+	res, err := s.controller.UpdatePermissions(ctx, req)
+	return res, Status(err)
+}
+
+// Reindex is the request handler for reindexing the files into a new version of the index.
+func (s Service) Reindex(ctx context.Context, req *pb.ReindexRequest) (*pb.ReindexResponse, error) {
+	res, err := s.controller.Reindex(ctx, req)
+	return res, Status(err)
+}
+
+// Migrate is the request handler for applying the pending index migrations.
+func (s Service) Migrate(ctx context.Context, req *pb.MigrateRequest) (*pb.MigrateResponse, error) {
+	res, err := s.controller.Migrate(ctx, req)
+	return res, Status(err)
+}
+
+// CreateSnapshot is the request handler for snapshotting the index to the snapshot repository.
+func (s Service) CreateSnapshot(ctx context.Context, req *pb.CreateSnapshotRequest) (*pb.Snapshot, error) {
+	res, err := s.controller.CreateSnapshot(ctx, req)
+	return res, Status(err)
+}
+
+// ListSnapshots is the request handler for listing the snapshots in the snapshot repository.
+func (s Service) ListSnapshots(
+	ctx context.Context,
+	req *pb.ListSnapshotsRequest,
+) (*pb.ListSnapshotsResponse, error) {
+	res, err := s.controller.ListSnapshots(ctx, req)
+	return res, Status(err)
+}
+
+// RestoreSnapshot is the request handler for restoring the index from a snapshot into new indices.
+func (s Service) RestoreSnapshot(
+	ctx context.Context,
+	req *pb.RestoreSnapshotRequest,
+) (*pb.RestoreSnapshotResponse, error) {
+	if err := validateRestoreSnapshot(req); err != nil {
+		return nil, Status(err)
+	}
+
+	res, err := s.controller.RestoreSnapshot(ctx, req)
+	return res, Status(err)
 }
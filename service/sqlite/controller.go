@@ -0,0 +1,36 @@
+//go:build sqlite_fts5
+// +build sqlite_fts5
+
+package sqlite
+
+import (
+	"github.com/meateam/search-service/service"
+)
+
+// Controller is the search service business logic implementation using the SQLite store.
+// The SQLite backend has no maintenance operations, so it doesn't support the admin operations.
+type Controller struct {
+	service.FileController
+	service.UnsupportedAdmin
+	store *Store
+}
+
+// NewController returns a new controller with a SQLite store keeping its files in the database file at path,
+// configured by opts.
+func NewController(path string, opts ...Option) (*Controller, error) {
+	store, err := NewStore(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Controller{
+		FileController:   service.NewFileController(store),
+		UnsupportedAdmin: service.UnsupportedAdmin{Backend: backendName},
+		store:            store,
+	}, nil
+}
+
+// Close closes the store's database.
+func (c Controller) Close() error {
+	return c.store.Close()
+}
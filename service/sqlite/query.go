@@ -0,0 +1,81 @@
+//go:build sqlite_fts5
+// +build sqlite_fts5
+
+package sqlite
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/meateam/search-service/service"
+)
+
+// searchSQL returns the statement selecting the ids of the files of tenant matching query, and its arguments.
+// Returns an empty statement if query can't match any file.
+// The words of the search term are matched as prefixes of the words of the files' text.
+func searchSQL(tenant string, query service.Query) (string, []interface{}) {
+	words := tokenize(query.Term)
+	if len(words) == 0 {
+		return "", nil
+	}
+
+	prefixes := make([]string, 0, len(words))
+	for _, word := range words {
+		prefixes = append(prefixes, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+
+	conditions := []string{"f.tenant = ?", "files_fts MATCH ?"}
+	args := []interface{}{tenant, strings.Join(prefixes, " OR ")}
+
+	switch query.Trashed {
+	case service.TrashedExclude:
+		conditions = append(conditions, "f.trashed = 0")
+	case service.TrashedOnly:
+		conditions = append(conditions, "f.trashed = 1")
+	}
+
+	// Scope the results to the files the user owns or was granted access to.
+	if query.UserID != "" {
+		access := "p.user_id = ?"
+		args = append(args, query.UserID, query.UserID)
+		if len(query.GroupIDs) > 0 {
+			access += " OR p.group_id IN (?" + strings.Repeat(", ?", len(query.GroupIDs)-1) + ")"
+			for _, groupID := range query.GroupIDs {
+				args = append(args, groupID)
+			}
+		}
+
+		conditions = append(conditions, "(f.owner_id = ? OR EXISTS (SELECT 1 FROM file_permissions p "+
+			"WHERE p.file_rowid = f.rowid AND ("+access+")))")
+	}
+
+	if query.OwnerID != "" {
+		conditions = append(conditions, "f.owner_id = ?")
+		args = append(args, query.OwnerID)
+	}
+
+	for _, tag := range query.Tags {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM file_tags t WHERE t.file_rowid = f.rowid AND t.tag = ?)")
+		args = append(args, tag)
+	}
+
+	for key, value := range query.Metadata {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM file_metadata m "+
+			"WHERE m.file_rowid = f.rowid AND m.key = ? AND m.value = ?)")
+		args = append(args, key, value)
+	}
+
+	args = append(args, service.SearchSize)
+
+	// Lower bm25 scores are more relevant.
+	return "SELECT f.id FROM files f JOIN files_fts ON files_fts.rowid = f.rowid WHERE " +
+		strings.Join(conditions, " AND ") +
+		" ORDER BY bm25(files_fts), f.id LIMIT ?", args
+}
+
+// tokenize splits text into its lowercase words, like the unicode61 tokenizer the text is indexed with.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
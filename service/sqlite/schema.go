@@ -0,0 +1,55 @@
+//go:build sqlite_fts5
+// +build sqlite_fts5
+
+package sqlite
+
+// schema creates the store's tables if they don't exist.
+// Files are stored as JSON in the files table, with the fields they are filtered by in columns,
+// their name, description and type in the files_fts FTS5 table sharing their rowid,
+// and their lists in tables referencing their rowid.
+const schema = `
+CREATE TABLE IF NOT EXISTS files (
+	rowid      INTEGER PRIMARY KEY,
+	tenant     TEXT NOT NULL,
+	id         TEXT NOT NULL,
+	source     TEXT NOT NULL,
+	owner_id   TEXT NOT NULL,
+	trashed    INTEGER NOT NULL,
+	deleted_at INTEGER NOT NULL,
+	UNIQUE (tenant, id)
+);
+
+CREATE INDEX IF NOT EXISTS files_owner_id ON files (tenant, owner_id);
+CREATE INDEX IF NOT EXISTS files_trashed ON files (trashed, deleted_at);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS files_fts USING fts5(
+	name,
+	description,
+	type,
+	tokenize = 'unicode61'
+);
+
+CREATE TABLE IF NOT EXISTS file_tags (
+	file_rowid INTEGER NOT NULL REFERENCES files (rowid),
+	tag        TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS file_tags_tag ON file_tags (tag, file_rowid);
+
+CREATE TABLE IF NOT EXISTS file_metadata (
+	file_rowid INTEGER NOT NULL REFERENCES files (rowid),
+	key        TEXT NOT NULL,
+	value      TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS file_metadata_key ON file_metadata (key, value, file_rowid);
+
+CREATE TABLE IF NOT EXISTS file_permissions (
+	file_rowid INTEGER NOT NULL REFERENCES files (rowid),
+	user_id    TEXT NOT NULL,
+	group_id   TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS file_permissions_user_id ON file_permissions (user_id, file_rowid);
+CREATE INDEX IF NOT EXISTS file_permissions_group_id ON file_permissions (group_id, file_rowid);
+`
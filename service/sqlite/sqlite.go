@@ -0,0 +1,11 @@
+// Package sqlite implements the search service backend keeping the files in a single SQLite database file,
+// searched with an FTS5 full text index, for edge deployments that can't run elasticsearch.
+//
+// The backend requires cgo and is only built with the sqlite_fts5 build tag,
+// which also enables FTS5 in the sqlite3 driver:
+//
+//	CGO_ENABLED=1 go build -tags sqlite_fts5
+package sqlite
+
+// backendName is the name of the SQLite backend.
+const backendName = "sqlite"
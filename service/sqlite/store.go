@@ -0,0 +1,501 @@
+//go:build sqlite_fts5
+// +build sqlite_fts5
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	pb "github.com/meateam/search-service/proto"
+	"github.com/meateam/search-service/service"
+
+	// Register the sqlite3 driver, built with FTS5 by the sqlite_fts5 build tag.
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// driverName is the name of the database/sql driver of sqlite.
+const driverName = "sqlite3"
+
+// Store is an implementation of service.Store, keeping the files in a single SQLite database file
+// and searching them using an FTS5 full text index over their name, description and type.
+// The files of all tenants are kept in the same tables, scoped by their tenant column.
+// Writes are visible to search right away, regardless of their refresh policy.
+type Store struct {
+	db *sql.DB
+
+	// tenants are the configured tenants, see WithTenants.
+	tenants []string
+}
+
+// Option configures optional behavior of the Store.
+type Option func(*Store)
+
+// WithTenants sets the tenants whose files are kept separately, selected by the request's tenant metadata,
+// which must be one of them. Defaults to no tenants, rejecting requests that have a tenant.
+func WithTenants(tenants ...string) Option {
+	return func(s *Store) {
+		s.tenants = tenants
+	}
+}
+
+// NewStore returns a new Store keeping its files in the database file at path,
+// which is created with the store's schema if it doesn't exist.
+func NewStore(path string, opts ...Option) (*Store, error) {
+	db, err := sql.Open(driverName, dataSourceName(path))
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer, so writes are serialized on a single connection
+	// rather than failing with busy errors.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed creating the sqlite schema: %v", err)
+	}
+
+	store := &Store{db: db}
+	for _, opt := range opts {
+		opt(store)
+	}
+
+	return store, nil
+}
+
+// dataSourceName returns the sqlite3 driver's URI of the database file at path,
+// escaping the path so that characters such as '?' and '#' are kept in the file name.
+func dataSourceName(path string) string {
+	uri := url.URL{
+		Scheme:   "file",
+		Opaque:   (&url.URL{Path: path}).EscapedPath(),
+		RawQuery: "_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL",
+	}
+
+	return uri.String()
+}
+
+// Close closes the store's database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// HealthCheck checks the health of the store, returns true if its database is usable, or false otherwise.
+func (s *Store) HealthCheck(ctx context.Context) (bool, error) {
+	if err := s.db.PingContext(ctx); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Get finds the file with the given id.
+// If successful returns the file and a nil error,
+// otherwise returns nil and non-nil error if any occurred.
+func (s *Store) Get(ctx context.Context, id string) (*pb.File, error) {
+	tenant, err := service.RequestTenant(ctx, s.tenants)
+	if err != nil {
+		return nil, err
+	}
+
+	file, _, err := getFile(ctx, s.db, tenant, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Exists finds which of the files with the given ids exist.
+// If successful returns the ids of the existing files and a nil error,
+// otherwise returns nil and non-nil error if any occurred.
+func (s *Store) Exists(ctx context.Context, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return []string{}, nil
+	}
+
+	tenant, err := service.RequestTenant(ctx, s.tenants)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []interface{}{tenant}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id FROM files WHERE tenant = ? AND id IN (?"+strings.Repeat(", ?", len(ids)-1)+")",
+		args...)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := scanIDs(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the order of the requested ids.
+	existing := make([]string, 0, len(found))
+	for _, id := range ids {
+		for _, foundID := range found {
+			if id == foundID {
+				existing = append(existing, id)
+				break
+			}
+		}
+	}
+
+	return existing, nil
+}
+
+// GetAll finds the files that match query,
+// if successful returns the ids of the files, and a nil error,
+// otherwise returns nil and non-nil error if any occurred.
+func (s *Store) GetAll(ctx context.Context, query service.Query) ([]string, error) {
+	tenant, err := service.RequestTenant(ctx, s.tenants)
+	if err != nil {
+		return nil, err
+	}
+
+	statement, args := searchSQL(tenant, query)
+	if statement == "" {
+		return []string{}, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanIDs(rows)
+}
+
+// Create creates a file, generating its id if it has none.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) Create(ctx context.Context, file *pb.File, refresh pb.Refresh) (string, error) {
+	tenant, err := service.RequestTenant(ctx, s.tenants)
+	if err != nil {
+		return "", err
+	}
+
+	if file.GetId() == "" {
+		id, err := service.NewFileID()
+		if err != nil {
+			return "", err
+		}
+
+		file = proto.Clone(file).(*pb.File)
+		file.Id = id
+	}
+
+	err = s.transaction(ctx, func(tx *sql.Tx) error {
+		// Creating an existing file replaces it.
+		_, rowid, err := getFile(ctx, tx, tenant, file.GetId())
+		if err != nil && !errors.Is(err, service.ErrNotFound) {
+			return err
+		}
+
+		if err == nil {
+			if err := deleteFile(ctx, tx, rowid); err != nil {
+				return err
+			}
+		}
+
+		return insertFile(ctx, tx, tenant, file)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return file.GetId(), nil
+}
+
+// Delete file from store by id.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) Delete(ctx context.Context, id string, refresh pb.Refresh) (string, error) {
+	tenant, err := service.RequestTenant(ctx, s.tenants)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.transaction(ctx, func(tx *sql.Tx) error {
+		_, rowid, err := getFile(ctx, tx, tenant, id)
+		if err != nil {
+			return err
+		}
+
+		return deleteFile(ctx, tx, rowid)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// Update partially updates the file with the fields set in file.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) Update(ctx context.Context, file *pb.File, refresh pb.Refresh) (string, error) {
+	return s.update(ctx, file.GetId(), func(stored *pb.File) {
+		service.MergeFile(stored, file)
+	})
+}
+
+// Trash marks the file as trashed at deletedAt.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) Trash(ctx context.Context, id string, deletedAt int64, refresh pb.Refresh) (string, error) {
+	return s.update(ctx, id, func(stored *pb.File) {
+		stored.Trashed = true
+		stored.DeletedAt = deletedAt
+	})
+}
+
+// Restore clears the trashed state of the file.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) Restore(ctx context.Context, id string, refresh pb.Refresh) (string, error) {
+	return s.update(ctx, id, func(stored *pb.File) {
+		stored.Trashed = false
+		stored.DeletedAt = 0
+	})
+}
+
+// UpdatePermissions replaces the permissions granted on the file.
+// If successful returns the file id and a nil error,
+// otherwise returns empty string and non-nil error if any occurred.
+func (s *Store) UpdatePermissions(
+	ctx context.Context,
+	id string,
+	permissions []*pb.Permission,
+	refresh pb.Refresh,
+) (string, error) {
+	return s.update(ctx, id, func(stored *pb.File) {
+		stored.Permissions = permissions
+	})
+}
+
+// PurgeTrashed permanently deletes all files that were trashed before trashedBefore,
+// of the tenant of the request in ctx, or of all tenants if ctx has none.
+// If successful returns the number of deleted files and a nil error,
+// otherwise returns 0 and non-nil error if any occurred.
+func (s *Store) PurgeTrashed(ctx context.Context, trashedBefore int64) (int64, error) {
+	statement := "SELECT rowid FROM files WHERE trashed = 1 AND deleted_at <= ?"
+	args := []interface{}{trashedBefore}
+	if service.TenantFromContext(ctx) != "" {
+		tenant, err := service.RequestTenant(ctx, s.tenants)
+		if err != nil {
+			return 0, err
+		}
+
+		statement += " AND tenant = ?"
+		args = append(args, tenant)
+	}
+
+	var purged int64
+	err := s.transaction(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, statement, args...)
+		if err != nil {
+			return err
+		}
+
+		rowids := make([]int64, 0)
+		for rows.Next() {
+			var rowid int64
+			if err := rows.Scan(&rowid); err != nil {
+				rows.Close()
+				return err
+			}
+
+			rowids = append(rowids, rowid)
+		}
+
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, rowid := range rowids {
+			if err := deleteFile(ctx, tx, rowid); err != nil {
+				return err
+			}
+		}
+
+		purged = int64(len(rowids))
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// update applies apply to the stored file with the given id and rewrites it.
+func (s *Store) update(ctx context.Context, id string, apply func(stored *pb.File)) (string, error) {
+	tenant, err := service.RequestTenant(ctx, s.tenants)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.transaction(ctx, func(tx *sql.Tx) error {
+		stored, rowid, err := getFile(ctx, tx, tenant, id)
+		if err != nil {
+			return err
+		}
+
+		apply(stored)
+		if err := deleteFile(ctx, tx, rowid); err != nil {
+			return err
+		}
+
+		return insertFile(ctx, tx, tenant, stored)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// transaction runs fn in a transaction, which is committed if fn succeeds or rolled back otherwise.
+func (s *Store) transaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// getFile returns the file of tenant with the given id, and its rowid.
+func getFile(ctx context.Context, q queryer, tenant string, id string) (*pb.File, int64, error) {
+	var rowid int64
+	var source string
+	err := q.QueryRowContext(ctx, "SELECT rowid, source FROM files WHERE tenant = ? AND id = ?", tenant, id).
+		Scan(&rowid, &source)
+	if err == sql.ErrNoRows {
+		return nil, 0, fmt.Errorf("file %s: %w", id, service.ErrNotFound)
+	}
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	file := &pb.File{}
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err := unmarshaler.Unmarshal(strings.NewReader(source), file); err != nil {
+		return nil, 0, err
+	}
+
+	return file, rowid, nil
+}
+
+// insertFile inserts file of tenant into the files table, its full text index and its lists' tables.
+func insertFile(ctx context.Context, tx *sql.Tx, tenant string, file *pb.File) error {
+	source, err := (&jsonpb.Marshaler{}).MarshalToString(file)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx,
+		"INSERT INTO files (tenant, id, source, owner_id, trashed, deleted_at) VALUES (?, ?, ?, ?, ?, ?)",
+		tenant, file.GetId(), source, file.GetOwnerID(), file.GetTrashed(), file.GetDeletedAt())
+	if err != nil {
+		return err
+	}
+
+	rowid, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO files_fts (rowid, name, description, type) VALUES (?, ?, ?, ?)",
+		rowid, file.GetName(), file.GetDescription(), file.GetType()); err != nil {
+		return err
+	}
+
+	for _, tag := range file.GetTags() {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO file_tags (file_rowid, tag) VALUES (?, ?)", rowid, tag); err != nil {
+			return err
+		}
+	}
+
+	for key, value := range file.GetMetadata() {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO file_metadata (file_rowid, key, value) VALUES (?, ?, ?)", rowid, key, value); err != nil {
+			return err
+		}
+	}
+
+	for _, permission := range file.GetPermissions() {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO file_permissions (file_rowid, user_id, group_id) VALUES (?, ?, ?)",
+			rowid, permission.GetUserID(), permission.GetGroupID()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteFile deletes the file with the given rowid from all of the store's tables.
+func deleteFile(ctx context.Context, tx *sql.Tx, rowid int64) error {
+	statements := []string{
+		"DELETE FROM file_tags WHERE file_rowid = ?",
+		"DELETE FROM file_metadata WHERE file_rowid = ?",
+		"DELETE FROM file_permissions WHERE file_rowid = ?",
+		"DELETE FROM files_fts WHERE rowid = ?",
+		"DELETE FROM files WHERE rowid = ?",
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement, rowid); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanIDs returns the ids of rows, and closes them.
+func scanIDs(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,72 @@
+//go:build sqlite_fts5
+// +build sqlite_fts5
+
+package sqlite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/meateam/search-service/proto"
+	"github.com/meateam/search-service/service"
+	"github.com/meateam/search-service/service/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) service.Store {
+		return newTestStore(t, filepath.Join(t.TempDir(), "search.db"))
+	})
+}
+
+func TestStoreTenants(t *testing.T) {
+	storetest.RunTenants(t, func(t *testing.T, tenants ...string) service.Store {
+		return newTestStore(t, filepath.Join(t.TempDir(), "search.db"), WithTenants(tenants...))
+	})
+}
+
+func TestNewStorePath(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{name: "plain", file: "search.db"},
+		{name: "question mark", file: "search?mode=ro.db"},
+		{name: "hash", file: "search#1.db"},
+		{name: "percent", file: "search%20.db"},
+		{name: "space", file: "search data.db"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			store := newTestStore(t, path)
+			file := &pb.File{Id: "1", Name: "report"}
+			if _, err := store.Create(context.Background(), file, pb.Refresh_REFRESH_DEFAULT); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			if _, err := os.Stat(path); err != nil {
+				t.Errorf("NewStore() didn't create the database file %s: %v", path, err)
+			}
+		})
+	}
+}
+
+// newTestStore returns a new store of the database file at path configured by opts,
+// which is closed when the test completes.
+func newTestStore(t *testing.T, path string, opts ...Option) *Store {
+	store, err := NewStore(path, opts...)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := store.Close(); err != nil {
+			t.Errorf("Close() error = %v", err)
+		}
+	})
+
+	return store
+}
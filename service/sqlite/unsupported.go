@@ -0,0 +1,36 @@
+//go:build !sqlite_fts5
+// +build !sqlite_fts5
+
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/meateam/search-service/service"
+)
+
+// Controller is the search service business logic implementation using the SQLite store,
+// which isn't available since the service was built without the sqlite_fts5 build tag.
+type Controller struct {
+	service.FileController
+	service.UnsupportedAdmin
+}
+
+// Option configures optional behavior of the SQLite store.
+type Option func()
+
+// WithTenants sets the tenants whose files are kept separately, which has no effect without the SQLite backend.
+func WithTenants(tenants ...string) Option {
+	return func() {}
+}
+
+// NewController returns an error, since the service was built without the SQLite backend.
+func NewController(path string, opts ...Option) (*Controller, error) {
+	return nil, fmt.Errorf("the %s backend isn't available, build with cgo enabled and `-tags sqlite_fts5`",
+		backendName)
+}
+
+// Close does nothing.
+func (c Controller) Close() error {
+	return nil
+}
@@ -2,14 +2,106 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"github.com/golang/protobuf/proto"
 	pb "github.com/meateam/search-service/proto"
 )
 
+// ErrNotFound is returned by a Store when the file an operation is made on doesn't exist.
+var ErrNotFound = errors.New("file not found")
+
+// SearchSize is the maximal number of results a Query returns, the most relevant ones.
+const SearchSize = 10
+
+// TrashedMode is how a Query treats trashed files.
+type TrashedMode int
+
+const (
+	// TrashedExclude excludes the trashed files from the results.
+	TrashedExclude TrashedMode = iota
+
+	// TrashedInclude includes the trashed files in the results.
+	TrashedInclude
+
+	// TrashedOnly returns only the trashed files.
+	TrashedOnly
+)
+
+// Query is a backend-neutral search for files. All of its set conditions must match.
+type Query struct {
+	// Term is matched against the file's text fields, such as its name and description.
+	Term string
+
+	// Trashed is how trashed files are treated, defaults to TrashedExclude.
+	Trashed TrashedMode
+
+	// UserID scopes the results to the files owned by the user or shared with the user
+	// or with any of GroupIDs, if set.
+	UserID   string
+	GroupIDs []string
+
+	// OwnerID scopes the results to the files owned by the owner, if set.
+	OwnerID string
+
+	// Tags are the tags the files must all have.
+	Tags []string
+
+	// Metadata are the metadata values the files must all have.
+	Metadata map[string]string
+}
+
 // Store is an interface for handling the storing of files.
+// Get, Delete, Update, Trash, Restore and UpdatePermissions return an error wrapping ErrNotFound
+// when the file doesn't exist. The conformance suite in package storetest checks an implementation
+// behaves as expected.
 type Store interface {
-	Create(ctx context.Context, file *pb.File) (string, error)
-	GetAll(ctx context.Context, filter interface{}) ([]string, error)
-	Delete(ctx context.Context, id string) (string, error)
-	Update(ctx context.Context, file *pb.File) (string, error)
+	Create(ctx context.Context, file *pb.File, refresh pb.Refresh) (string, error)
+	Get(ctx context.Context, id string) (*pb.File, error)
+	GetAll(ctx context.Context, query Query) ([]string, error)
+	Exists(ctx context.Context, ids []string) ([]string, error)
+	Delete(ctx context.Context, id string, refresh pb.Refresh) (string, error)
+	Update(ctx context.Context, file *pb.File, refresh pb.Refresh) (string, error)
+	Trash(ctx context.Context, id string, deletedAt int64, refresh pb.Refresh) (string, error)
+	Restore(ctx context.Context, id string, refresh pb.Refresh) (string, error)
+	UpdatePermissions(
+		ctx context.Context,
+		id string,
+		permissions []*pb.Permission,
+		refresh pb.Refresh,
+	) (string, error)
+	PurgeTrashed(ctx context.Context, trashedBefore int64) (int64, error)
 	HealthCheck(ctx context.Context) (bool, error)
 }
+
+// MergeFile applies the partial update of a file to the stored file, for stores that don't
+// support partial updates natively. The fields set in update replace the stored ones,
+// except for metadata which is merged, like in a partial update of an elasticsearch document.
+func MergeFile(stored *pb.File, update *pb.File) {
+	// Lists are replaced rather than appended to.
+	if len(update.GetChildren()) > 0 {
+		stored.Children = nil
+	}
+
+	if len(update.GetPermissions()) > 0 {
+		stored.Permissions = nil
+	}
+
+	if len(update.GetTags()) > 0 {
+		stored.Tags = nil
+	}
+
+	proto.Merge(stored, update)
+}
+
+// NewFileID returns a random id for a file created without one, for stores that don't generate ids.
+func NewFileID() (string, error) {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
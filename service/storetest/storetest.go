@@ -0,0 +1,439 @@
+// Package storetest implements a conformance suite for the implementations of service.Store.
+// Every backend is expected to pass it, i.e from a test in the backend's package:
+//
+//	func TestStore(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T) service.Store {
+//			return newEmptyStore(t)
+//		})
+//	}
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	pb "github.com/meateam/search-service/proto"
+	"github.com/meateam/search-service/service"
+	"google.golang.org/grpc/metadata"
+)
+
+// refresh is the refresh policy of the suite's writes, so they are searchable right away.
+const refresh = pb.Refresh_REFRESH_IMMEDIATE
+
+// Run runs the conformance suite against the stores returned by newStore,
+// which must return a new empty store on each call.
+func Run(t *testing.T, newStore func(t *testing.T) service.Store) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, store service.Store)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"GetMissing", testGetMissing},
+		{"Update", testUpdate},
+		{"Delete", testDelete},
+		{"Exists", testExists},
+		{"SearchTerm", testSearchTerm},
+		{"SearchTrashed", testSearchTrashed},
+		{"SearchAccess", testSearchAccess},
+		{"SearchOwner", testSearchOwner},
+		{"SearchTagsAndMetadata", testSearchTagsAndMetadata},
+		{"SearchSize", testSearchSize},
+		{"UpdatePermissions", testUpdatePermissions},
+		{"PurgeTrashed", testPurgeTrashed},
+		{"HealthCheck", testHealthCheck},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newStore(t))
+		})
+	}
+}
+
+// RunTenants runs the tenants conformance suite against the stores returned by newStore,
+// which must return a new empty store configured with the given tenants on each call.
+func RunTenants(t *testing.T, newStore func(t *testing.T, tenants ...string) service.Store) {
+	t.Run("TenantIsolation", func(t *testing.T) {
+		testTenantIsolation(t, newStore(t, "acme", "globex"))
+	})
+
+	t.Run("TenantRejected", func(t *testing.T) {
+		testTenantRejected(t, newStore(t, "acme"), "", "globex")
+	})
+
+	t.Run("TenantsDisabled", func(t *testing.T) {
+		testTenantRejected(t, newStore(t), "acme")
+	})
+}
+
+func testTenantIsolation(t *testing.T, store service.Store) {
+	acme, globex := tenantContext("acme"), tenantContext("globex")
+	for ctx, id := range map[context.Context]string{acme: "1", globex: "2"} {
+		if _, err := store.Create(ctx, &pb.File{Id: id, Name: "report", OwnerID: "owner"}, refresh); err != nil {
+			t.Fatalf("Create(%s) error = %v", id, err)
+		}
+	}
+
+	if _, err := store.Get(globex, "1"); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("Get() of another tenant's file error = %v, want %v", err, service.ErrNotFound)
+	}
+
+	got, err := store.GetAll(acme, service.Query{Term: "report"})
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+
+	assertIDs(t, "GetAll()", got, "1")
+
+	for ctx, id := range map[context.Context]string{acme: "1", globex: "2"} {
+		if _, err := store.Trash(ctx, id, 1000, refresh); err != nil {
+			t.Fatalf("Trash(%s) error = %v", id, err)
+		}
+	}
+
+	// A purge without a tenant purges the files of all of the tenants.
+	purged, err := store.PurgeTrashed(context.Background(), 2000)
+	if err != nil {
+		t.Fatalf("PurgeTrashed() error = %v", err)
+	}
+
+	if purged != 2 {
+		t.Errorf("PurgeTrashed() = %d, want 2", purged)
+	}
+}
+
+// testTenantRejected asserts that store rejects the requests of each of tenants as an invalid tenant field.
+func testTenantRejected(t *testing.T, store service.Store, tenants ...string) {
+	for _, tenant := range tenants {
+		ctx := tenantContext(tenant)
+		_, err := store.Create(ctx, &pb.File{Id: "1", Name: "report", OwnerID: "owner"}, refresh)
+		assertInvalidTenant(t, "Create()", tenant, err)
+
+		_, err = store.Get(ctx, "1")
+		assertInvalidTenant(t, "Get()", tenant, err)
+
+		_, err = store.GetAll(ctx, service.Query{Term: "report"})
+		assertInvalidTenant(t, "GetAll()", tenant, err)
+	}
+}
+
+// tenantContext returns a context of an incoming request of tenant, or without a tenant if it's empty.
+func tenantContext(tenant string) context.Context {
+	if tenant == "" {
+		return context.Background()
+	}
+
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(service.TenantMetadataKey, tenant))
+}
+
+// assertInvalidTenant asserts that err is a ValidationError of the tenant metadata.
+func assertInvalidTenant(t *testing.T, name string, tenant string, err error) {
+	t.Helper()
+	var validationErr *service.ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Violations[0].Field != service.TenantMetadataKey {
+		t.Errorf("%s of tenant %q error = %v, want an invalid %s", name, tenant, err, service.TenantMetadataKey)
+	}
+}
+
+func testCreateAndGet(t *testing.T, store service.Store) {
+	ctx := context.Background()
+	want := &pb.File{
+		Id:          "1",
+		Name:        "quarterly report",
+		Description: "numbers",
+		OwnerID:     "owner",
+		Size:        42,
+		CreatedAt:   1000,
+		Tags:        []string{"finance"},
+		Metadata:    map[string]string{"project": "x"},
+	}
+
+	id, err := store.Create(ctx, want, refresh)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if id != want.GetId() {
+		t.Errorf("Create() = %s, want %s", id, want.GetId())
+	}
+
+	got, err := store.Get(ctx, want.GetId())
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if got.GetName() != want.GetName() ||
+		got.GetDescription() != want.GetDescription() ||
+		got.GetOwnerID() != want.GetOwnerID() ||
+		got.GetSize() != want.GetSize() ||
+		got.GetCreatedAt() != want.GetCreatedAt() ||
+		!reflect.DeepEqual(got.GetTags(), want.GetTags()) ||
+		!reflect.DeepEqual(got.GetMetadata(), want.GetMetadata()) {
+		t.Errorf("Get() = %v, want %v", got, want)
+	}
+}
+
+func testGetMissing(t *testing.T, store service.Store) {
+	if _, err := store.Get(context.Background(), "missing"); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, service.ErrNotFound)
+	}
+}
+
+func testUpdate(t *testing.T, store service.Store) {
+	ctx := context.Background()
+	create(t, store, &pb.File{Id: "1", Name: "draft", OwnerID: "owner"})
+
+	if _, err := store.Update(ctx, &pb.File{Id: "1", Name: "final"}, refresh); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	got, err := store.Get(ctx, "1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	// Update is partial, so the fields that aren't set are kept.
+	if got.GetName() != "final" || got.GetOwnerID() != "owner" {
+		t.Errorf("Get() = %v, want name final and ownerID owner", got)
+	}
+
+	_, err = store.Update(ctx, &pb.File{Id: "missing", Name: "x"}, refresh)
+	if !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("Update() error = %v, want %v", err, service.ErrNotFound)
+	}
+}
+
+func testDelete(t *testing.T, store service.Store) {
+	ctx := context.Background()
+	create(t, store, &pb.File{Id: "1", Name: "report"})
+
+	if _, err := store.Delete(ctx, "1", refresh); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := store.Get(ctx, "1"); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, service.ErrNotFound)
+	}
+
+	if _, err := store.Delete(ctx, "1", refresh); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("Delete() error = %v, want %v", err, service.ErrNotFound)
+	}
+}
+
+func testExists(t *testing.T, store service.Store) {
+	create(t, store, &pb.File{Id: "1", Name: "a"}, &pb.File{Id: "2", Name: "b"})
+
+	got, err := store.Exists(context.Background(), []string{"1", "2", "3"})
+	if err != nil {
+		t.Fatalf("Exists() error = %v", err)
+	}
+
+	assertIDs(t, "Exists()", got, "1", "2")
+}
+
+func testSearchTerm(t *testing.T, store service.Store) {
+	create(t, store,
+		&pb.File{Id: "1", Name: "quarterly report"},
+		&pb.File{Id: "2", Name: "holiday photos"},
+		&pb.File{Id: "3", Name: "notes", Description: "meeting report"},
+	)
+
+	assertSearch(t, store, service.Query{Term: "report"}, "1", "3")
+	assertSearch(t, store, service.Query{Term: "photos"}, "2")
+	assertSearch(t, store, service.Query{Term: "nothing"})
+}
+
+func testSearchTrashed(t *testing.T, store service.Store) {
+	ctx := context.Background()
+	create(t, store, &pb.File{Id: "1", Name: "report"}, &pb.File{Id: "2", Name: "report"})
+
+	if _, err := store.Trash(ctx, "1", 1000, refresh); err != nil {
+		t.Fatalf("Trash() error = %v", err)
+	}
+
+	assertSearch(t, store, service.Query{Term: "report"}, "2")
+	assertSearch(t, store, service.Query{Term: "report", Trashed: service.TrashedInclude}, "1", "2")
+	assertSearch(t, store, service.Query{Term: "report", Trashed: service.TrashedOnly}, "1")
+
+	trashed, err := store.Get(ctx, "1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if !trashed.GetTrashed() || trashed.GetDeletedAt() != 1000 {
+		t.Errorf("Get() = %v, want trashed at 1000", trashed)
+	}
+
+	if _, err := store.Restore(ctx, "1", refresh); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	assertSearch(t, store, service.Query{Term: "report"}, "1", "2")
+
+	if _, err := store.Trash(ctx, "missing", 1000, refresh); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("Trash() error = %v, want %v", err, service.ErrNotFound)
+	}
+}
+
+func testSearchAccess(t *testing.T, store service.Store) {
+	create(t, store,
+		&pb.File{Id: "1", Name: "report", OwnerID: "alice"},
+		&pb.File{Id: "2", Name: "report", OwnerID: "bob", Permissions: []*pb.Permission{{UserID: "alice"}}},
+		&pb.File{Id: "3", Name: "report", OwnerID: "bob", Permissions: []*pb.Permission{{GroupID: "team"}}},
+		&pb.File{Id: "4", Name: "report", OwnerID: "bob"},
+	)
+
+	assertSearch(t, store, service.Query{Term: "report", UserID: "alice"}, "1", "2")
+	assertSearch(t, store, service.Query{Term: "report", UserID: "alice", GroupIDs: []string{"team"}}, "1", "2", "3")
+	assertSearch(t, store, service.Query{Term: "report", UserID: "bob"}, "2", "3", "4")
+}
+
+func testSearchOwner(t *testing.T, store service.Store) {
+	create(t, store,
+		&pb.File{Id: "1", Name: "report", OwnerID: "alice"},
+		&pb.File{Id: "2", Name: "report", OwnerID: "bob"},
+	)
+
+	assertSearch(t, store, service.Query{Term: "report", OwnerID: "alice"}, "1")
+}
+
+func testSearchTagsAndMetadata(t *testing.T, store service.Store) {
+	create(t, store,
+		&pb.File{Id: "1", Name: "report", Tags: []string{"finance", "q1"}, Metadata: map[string]string{"project": "x"}},
+		&pb.File{Id: "2", Name: "report", Tags: []string{"finance"}, Metadata: map[string]string{"project": "y"}},
+		&pb.File{Id: "3", Name: "report"},
+	)
+
+	assertSearch(t, store, service.Query{Term: "report", Tags: []string{"finance"}}, "1", "2")
+	assertSearch(t, store, service.Query{Term: "report", Tags: []string{"finance", "q1"}}, "1")
+	assertSearch(t, store, service.Query{Term: "report", Metadata: map[string]string{"project": "y"}}, "2")
+}
+
+func testSearchSize(t *testing.T, store service.Store) {
+	files := make([]*pb.File, 0, service.SearchSize+1)
+	for i := 0; i <= service.SearchSize; i++ {
+		files = append(files, &pb.File{Id: fmt.Sprintf("page-%d", i), Name: "page"})
+	}
+
+	create(t, store, files...)
+
+	got, err := store.GetAll(context.Background(), service.Query{Term: "page"})
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+
+	if len(got) != service.SearchSize {
+		t.Errorf("GetAll() returned %d files, want %d", len(got), service.SearchSize)
+	}
+}
+
+func testUpdatePermissions(t *testing.T, store service.Store) {
+	ctx := context.Background()
+	create(t, store, &pb.File{
+		Id:          "1",
+		Name:        "report",
+		OwnerID:     "bob",
+		Permissions: []*pb.Permission{{UserID: "alice"}},
+	})
+
+	if _, err := store.UpdatePermissions(ctx, "1", []*pb.Permission{{UserID: "carol"}}, refresh); err != nil {
+		t.Fatalf("UpdatePermissions() error = %v", err)
+	}
+
+	assertSearch(t, store, service.Query{Term: "report", UserID: "alice"})
+	assertSearch(t, store, service.Query{Term: "report", UserID: "carol"}, "1")
+
+	if _, err := store.UpdatePermissions(ctx, "1", nil, refresh); err != nil {
+		t.Fatalf("UpdatePermissions() error = %v", err)
+	}
+
+	assertSearch(t, store, service.Query{Term: "report", UserID: "carol"})
+
+	_, err := store.UpdatePermissions(ctx, "missing", nil, refresh)
+	if !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("UpdatePermissions() error = %v, want %v", err, service.ErrNotFound)
+	}
+}
+
+func testPurgeTrashed(t *testing.T, store service.Store) {
+	ctx := context.Background()
+	create(t, store,
+		&pb.File{Id: "1", Name: "report"},
+		&pb.File{Id: "2", Name: "report"},
+		&pb.File{Id: "3", Name: "report"},
+	)
+
+	for id, deletedAt := range map[string]int64{"1": 1000, "2": 3000} {
+		if _, err := store.Trash(ctx, id, deletedAt, refresh); err != nil {
+			t.Fatalf("Trash() error = %v", err)
+		}
+	}
+
+	purged, err := store.PurgeTrashed(ctx, 2000)
+	if err != nil {
+		t.Fatalf("PurgeTrashed() error = %v", err)
+	}
+
+	if purged != 1 {
+		t.Errorf("PurgeTrashed() = %d, want 1", purged)
+	}
+
+	got, err := store.Exists(ctx, []string{"1", "2", "3"})
+	if err != nil {
+		t.Fatalf("Exists() error = %v", err)
+	}
+
+	assertIDs(t, "Exists()", got, "2", "3")
+}
+
+func testHealthCheck(t *testing.T, store service.Store) {
+	healthy, err := store.HealthCheck(context.Background())
+	if err != nil {
+		t.Fatalf("HealthCheck() error = %v", err)
+	}
+
+	if !healthy {
+		t.Error("HealthCheck() = false, want true")
+	}
+}
+
+// create creates files in store, failing the test if any of them couldn't be created.
+func create(t *testing.T, store service.Store, files ...*pb.File) {
+	t.Helper()
+	for _, file := range files {
+		if _, err := store.Create(context.Background(), file, refresh); err != nil {
+			t.Fatalf("Create(%s) error = %v", file.GetId(), err)
+		}
+	}
+}
+
+// assertSearch asserts that searching store for query returns exactly the files with the given ids.
+func assertSearch(t *testing.T, store service.Store, query service.Query, ids ...string) {
+	t.Helper()
+	got, err := store.GetAll(context.Background(), query)
+	if err != nil {
+		t.Fatalf("GetAll(%+v) error = %v", query, err)
+	}
+
+	assertIDs(t, "GetAll()", got, ids...)
+}
+
+// assertIDs asserts that got has exactly the given ids, in any order.
+func assertIDs(t *testing.T, name string, got []string, ids ...string) {
+	t.Helper()
+	sorted := append([]string{}, got...)
+	sort.Strings(sorted)
+	sort.Strings(ids)
+	if len(sorted) == 0 && len(ids) == 0 {
+		return
+	}
+
+	if !reflect.DeepEqual(sorted, ids) {
+		t.Errorf("%s = %v, want %v", name, sorted, ids)
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// TenantMetadataKey is the grpc metadata key of the tenant a request is made for.
+const TenantMetadataKey = "tenant"
+
+// TenantFromContext returns the tenant of the incoming grpc request in ctx,
+// or an empty string if the request has none.
+func TenantFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	tenants := md.Get(TenantMetadataKey)
+	if len(tenants) == 0 {
+		return ""
+	}
+
+	return tenants[0]
+}
+
+// RequestTenant returns the tenant of the incoming grpc request in ctx, which must be one of tenants,
+// the tenants a store is configured with, or none if it's configured without tenants.
+// Returns an InvalidField error of TenantMetadataKey if the request's tenant isn't allowed.
+func RequestTenant(ctx context.Context, tenants []string) (string, error) {
+	tenant := TenantFromContext(ctx)
+	if len(tenants) == 0 {
+		if tenant != "" {
+			return "", InvalidField(TenantMetadataKey, "tenants are not enabled, got tenant %s", tenant)
+		}
+
+		return "", nil
+	}
+
+	if tenant == "" {
+		return "", InvalidField(TenantMetadataKey, "tenant is required")
+	}
+
+	for _, t := range tenants {
+		if t == tenant {
+			return tenant, nil
+		}
+	}
+
+	return "", InvalidField(TenantMetadataKey, "unknown tenant %s", tenant)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "github.com/meateam/search-service/proto"
+)
+
+// ErrUnsupported is returned for an operation the configured backend doesn't support.
+var ErrUnsupported = errors.New("operation not supported")
+
+// UnsupportedAdmin implements the admin operations of Controller for backends without indices
+// to maintain. Its index maintenance is a no-op, and its admin RPCs return an error wrapping ErrUnsupported.
+type UnsupportedAdmin struct {
+	// Backend is the name of the backend reported in the errors.
+	Backend string
+}
+
+// MappingDrift returns no drift, since the backend has no mappings.
+func (a UnsupportedAdmin) MappingDrift(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+// Rollover rolls over nothing, since the backend has no indices to roll over.
+func (a UnsupportedAdmin) Rollover(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+// Reindex returns an error wrapping ErrUnsupported.
+func (a UnsupportedAdmin) Reindex(ctx context.Context, req *pb.ReindexRequest) (*pb.ReindexResponse, error) {
+	return nil, a.unsupported("reindex")
+}
+
+// Migrate returns an error wrapping ErrUnsupported.
+func (a UnsupportedAdmin) Migrate(ctx context.Context, req *pb.MigrateRequest) (*pb.MigrateResponse, error) {
+	return nil, a.unsupported("migrate")
+}
+
+// CreateSnapshot returns an error wrapping ErrUnsupported.
+func (a UnsupportedAdmin) CreateSnapshot(
+	ctx context.Context,
+	req *pb.CreateSnapshotRequest,
+) (*pb.Snapshot, error) {
+	return nil, a.unsupported("snapshots")
+}
+
+// ListSnapshots returns an error wrapping ErrUnsupported.
+func (a UnsupportedAdmin) ListSnapshots(
+	ctx context.Context,
+	req *pb.ListSnapshotsRequest,
+) (*pb.ListSnapshotsResponse, error) {
+	return nil, a.unsupported("snapshots")
+}
+
+// RestoreSnapshot returns an error wrapping ErrUnsupported.
+func (a UnsupportedAdmin) RestoreSnapshot(
+	ctx context.Context,
+	req *pb.RestoreSnapshotRequest,
+) (*pb.RestoreSnapshotResponse, error) {
+	return nil, a.unsupported("snapshots")
+}
+
+// unsupported returns the error of the unsupported operation.
+func (a UnsupportedAdmin) unsupported(operation string) error {
+	return fmt.Errorf("%s of the %s backend: %w", operation, a.Backend, ErrUnsupported)
+}
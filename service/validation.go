@@ -0,0 +1,162 @@
+package service
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	pb "github.com/meateam/search-service/proto"
+)
+
+const (
+	// maxIDLength is the maximal length in bytes of a file id, which is elasticsearch's limit of document ids.
+	maxIDLength = 512
+
+	// maxNameLength is the maximal length in characters of a file name.
+	maxNameLength = 255
+
+	// maxTermLength is the maximal length in characters of a search term.
+	maxTermLength = 256
+
+	// maxExistsIDs is the maximal number of files checked by a single Exists request.
+	maxExistsIDs = 1000
+)
+
+// validator collects the field violations of a request.
+type validator struct {
+	violations []FieldViolation
+}
+
+// check adds the violation of field described by format and args if ok is false.
+func (v *validator) check(ok bool, field string, format string, args ...interface{}) {
+	if !ok {
+		v.violations = append(v.violations, FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+	}
+}
+
+// id checks that the file id in field is set and isn't too long.
+func (v *validator) id(field string, id string) {
+	v.check(id != "", field, "%s is required", field)
+	v.check(len(id) <= maxIDLength, field, "%s must be at most %d bytes", field, maxIDLength)
+}
+
+// refresh checks that refresh is one of the defined refresh policies.
+func (v *validator) refresh(refresh pb.Refresh) {
+	_, ok := pb.Refresh_name[int32(refresh)]
+	v.check(ok, "refresh", "invalid refresh %d", refresh)
+}
+
+// permissions checks that each of the permissions is granted to a user or a group.
+func (v *validator) permissions(permissions []*pb.Permission) {
+	for i, permission := range permissions {
+		v.check(permission.GetUserID() != "" || permission.GetGroupID() != "",
+			fmt.Sprintf("permissions[%d]", i), "permission userID or groupID is required")
+	}
+}
+
+// err returns a ValidationError of the collected violations, or nil if there are none.
+func (v *validator) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Violations: v.violations}
+}
+
+// file checks the fields of a created or updated file that are set.
+func (v *validator) file(file *pb.File) {
+	v.id("id", file.GetId())
+	v.check(utf8.RuneCountInString(file.GetName()) <= maxNameLength,
+		"name", "name must be at most %d characters", maxNameLength)
+	v.check(file.GetSize() >= 0, "size", "size must not be negative")
+	v.check(file.GetCreatedAt() >= 0, "createdAt", "createdAt must not be negative")
+	v.check(file.GetUpdatedAt() >= 0, "updatedAt", "updatedAt must not be negative")
+	v.check(file.GetCreatedAt() == 0 || file.GetUpdatedAt() == 0 || file.GetCreatedAt() <= file.GetUpdatedAt(),
+		"updatedAt", "updatedAt must not be before createdAt")
+	v.permissions(file.GetPermissions())
+	for i, tag := range file.GetTags() {
+		v.check(tag != "", fmt.Sprintf("tags[%d]", i), "tag must not be empty")
+	}
+
+	for key := range file.GetMetadata() {
+		v.check(key != "", "metadata", "metadata key must not be empty")
+	}
+
+	v.refresh(file.GetRefresh())
+}
+
+// validateCreateFile validates a CreateFile request, which must have the id and owner of the file.
+func validateCreateFile(req *pb.File) error {
+	v := &validator{}
+	v.file(req)
+	v.check(req.GetOwnerID() != "", "ownerID", "ownerID is required")
+
+	return v.err()
+}
+
+// validateUpdate validates an Update request, whose fields are optional except for the id.
+func validateUpdate(req *pb.File) error {
+	v := &validator{}
+	v.file(req)
+
+	return v.err()
+}
+
+// validateSearch validates a Search request.
+func validateSearch(req *pb.SearchRequest) error {
+	v := &validator{}
+	v.check(utf8.RuneCountInString(req.GetTerm()) <= maxTermLength,
+		"term", "term must be at most %d characters", maxTermLength)
+
+	for i, filter := range req.GetFilters() {
+		err := parseFilter(filter, &Query{})
+		v.check(err == nil, fmt.Sprintf("filters[%d]", i), "%v", err)
+	}
+
+	return v.err()
+}
+
+// validateExists validates an Exists request.
+func validateExists(req *pb.ExistsRequest) error {
+	v := &validator{}
+	v.check(len(req.GetIds()) <= maxExistsIDs, "ids", "at most %d ids can be checked at once", maxExistsIDs)
+	for i, id := range req.GetIds() {
+		v.id(fmt.Sprintf("ids[%d]", i), id)
+	}
+
+	return v.err()
+}
+
+// validateID validates a request reading the file with the given id.
+func validateID(id string) error {
+	v := &validator{}
+	v.id("id", id)
+
+	return v.err()
+}
+
+// validateWrite validates a request writing the file with the given id with refresh.
+func validateWrite(id string, refresh pb.Refresh) error {
+	v := &validator{}
+	v.id("id", id)
+	v.refresh(refresh)
+
+	return v.err()
+}
+
+// validateUpdatePermissions validates an UpdatePermissions request.
+func validateUpdatePermissions(req *pb.UpdatePermissionsRequest) error {
+	v := &validator{}
+	v.id("id", req.GetId())
+	v.permissions(req.GetPermissions())
+	v.refresh(req.GetRefresh())
+
+	return v.err()
+}
+
+// validateRestoreSnapshot validates a RestoreSnapshot request, which must have the snapshot's name.
+func validateRestoreSnapshot(req *pb.RestoreSnapshotRequest) error {
+	v := &validator{}
+	v.check(req.GetName() != "", "name", "name is required")
+
+	return v.err()
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/meateam/search-service/proto"
+)
+
+// violatedFields returns the fields of the violations of err, or nil if err isn't a ValidationError.
+func violatedFields(err error) []string {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		return nil
+	}
+
+	fields := make([]string, 0, len(validationErr.Violations))
+	for _, violation := range validationErr.Violations {
+		fields = append(fields, violation.Field)
+	}
+
+	return fields
+}
+
+func TestValidateCreateFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       *pb.File
+		wantFields []string
+	}{
+		{
+			name: "valid",
+			file: &pb.File{Id: "file", OwnerID: "owner", Name: "file.txt", Size: 1, CreatedAt: 1, UpdatedAt: 2},
+		},
+		{
+			name:       "missing id and owner",
+			file:       &pb.File{},
+			wantFields: []string{"id", "ownerID"},
+		},
+		{
+			name: "id at the max length",
+			file: &pb.File{Id: strings.Repeat("a", maxIDLength), OwnerID: "owner"},
+		},
+		{
+			name:       "id above the max length",
+			file:       &pb.File{Id: strings.Repeat("a", maxIDLength+1), OwnerID: "owner"},
+			wantFields: []string{"id"},
+		},
+		{
+			name: "name at the max length",
+			file: &pb.File{Id: "file", OwnerID: "owner", Name: strings.Repeat("ש", maxNameLength)},
+		},
+		{
+			name:       "name above the max length",
+			file:       &pb.File{Id: "file", OwnerID: "owner", Name: strings.Repeat("a", maxNameLength+1)},
+			wantFields: []string{"name"},
+		},
+		{
+			name:       "negative size",
+			file:       &pb.File{Id: "file", OwnerID: "owner", Size: -1},
+			wantFields: []string{"size"},
+		},
+		{
+			name:       "updated before created",
+			file:       &pb.File{Id: "file", OwnerID: "owner", CreatedAt: 2, UpdatedAt: 1},
+			wantFields: []string{"updatedAt"},
+		},
+		{
+			name:       "permission without a user or group",
+			file:       &pb.File{Id: "file", OwnerID: "owner", Permissions: []*pb.Permission{{}}},
+			wantFields: []string{"permissions[0]"},
+		},
+		{
+			name:       "empty tag",
+			file:       &pb.File{Id: "file", OwnerID: "owner", Tags: []string{"tag", ""}},
+			wantFields: []string{"tags[1]"},
+		},
+		{
+			name:       "invalid refresh",
+			file:       &pb.File{Id: "file", OwnerID: "owner", Refresh: pb.Refresh(-1)},
+			wantFields: []string{"refresh"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := violatedFields(validateCreateFile(tt.file)); !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("validateCreateFile() violated fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestValidateSearch(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *pb.SearchRequest
+		wantFields []string
+	}{
+		{
+			name: "valid",
+			req:  &pb.SearchRequest{Term: "term"},
+		},
+		{
+			name: "term at the max length",
+			req:  &pb.SearchRequest{Term: strings.Repeat("ש", maxTermLength)},
+		},
+		{
+			name:       "term above the max length",
+			req:        &pb.SearchRequest{Term: strings.Repeat("a", maxTermLength+1)},
+			wantFields: []string{"term"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := violatedFields(validateSearch(tt.req)); !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("validateSearch() violated fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestValidateExists(t *testing.T) {
+	ids := func(n int) []string {
+		ids := make([]string, n)
+		for i := range ids {
+			ids[i] = "file"
+		}
+
+		return ids
+	}
+
+	tests := []struct {
+		name       string
+		req        *pb.ExistsRequest
+		wantFields []string
+	}{
+		{
+			name: "ids at the max count",
+			req:  &pb.ExistsRequest{Ids: ids(maxExistsIDs)},
+		},
+		{
+			name:       "ids above the max count",
+			req:        &pb.ExistsRequest{Ids: ids(maxExistsIDs + 1)},
+			wantFields: []string{"ids"},
+		},
+		{
+			name:       "empty id",
+			req:        &pb.ExistsRequest{Ids: []string{"file", ""}},
+			wantFields: []string{"ids[1]"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := violatedFields(validateExists(tt.req)); !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("validateExists() violated fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestValidateRestoreSnapshot(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *pb.RestoreSnapshotRequest
+		wantFields []string
+	}{
+		{
+			name: "valid",
+			req:  &pb.RestoreSnapshotRequest{Name: "snapshot"},
+		},
+		{
+			name:       "missing name",
+			req:        &pb.RestoreSnapshotRequest{},
+			wantFields: []string{"name"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := violatedFields(validateRestoreSnapshot(tt.req)); !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("validateRestoreSnapshot() violated fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}